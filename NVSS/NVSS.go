@@ -55,7 +55,7 @@ func NewCatalogFromText(filename string) (*Catalog, error) {
 
 	var ra_h, ra_m, ra_s, dec_d, dec_m, dec_s, flux float64
 
-	fmt.Printf(fmt.Sprintf("Scanning %s\n", filename))
+	fmt.Printf("Scanning %s\n", filename)
 	for scanner.Scan() {
 		var line string = scanner.Text()
 		if len(line) == 0 {
@@ -87,8 +87,12 @@ func NewCatalogFromText(filename string) (*Catalog, error) {
 			}
 			ra := coordinate.NewAngleFromHMS(ra_h, ra_m, ra_s)
 			dec := coordinate.NewAngleFromDMS(dec_d, dec_m, dec_s)
+			coord, err := coordinate.CoordinateOfAngles(`J2000`, ra, dec)
+			if err != nil {
+				return nil, err
+			}
 			source := Source{
-				Coord: coordinate.NewCoordinateFromAngles(`J2000`, ra, dec),
+				Coord: coord,
 				Flux:  flux,
 			}
 			sources = append(sources, source)
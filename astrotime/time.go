@@ -0,0 +1,197 @@
+package astrotime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type Scale string
+
+const (
+	UTC Scale = `UTC` // Coordinated Universal Time
+	TAI Scale = `TAI` // International Atomic Time
+	TT  Scale = `TT`  // Terrestrial Time
+	TDB Scale = `TDB` // Barycentric Dynamical Time
+	UT1 Scale = `UT1` // Universal Time, following the rotation of the Earth
+
+	JD_J2000     float64 = 2451545.0
+	JD_UNIX      float64 = 2440587.5 // 1970-01-01T00:00:00 UTC
+	MJD_OFFSET   float64 = 2400000.5
+	SEC_PER_DAY  float64 = 86400.
+	TT_MINUS_TAI float64 = 32.184
+)
+
+var (
+	ErrUnknownScale = errors.New(`unknown time scale`)
+)
+
+func ParseScale(scale string) (Scale, error) {
+	switch s := Scale(strings.ToUpper(scale)); s {
+	case UTC, TAI, TT, TDB, UT1:
+		return s, nil
+	}
+	return ``, fmt.Errorf("%w %q", ErrUnknownScale, scale)
+}
+
+/* Instant */
+// Instant is a point in time, kept as a two-part Julian date in TAI
+// so that sub-millisecond differences survive conversions between scales.
+// UT1 is derived from UTC with DUT1 = UT1-UTC, which is zero unless set with WithDUT1.
+type Instant struct {
+	jd1  float64
+	jd2  float64
+	dut1 float64
+}
+
+func Now() Instant {
+	return FromTime(time.Now())
+}
+
+// FromTime returns the instant of t, which is taken as UTC.
+func FromTime(t time.Time) Instant {
+	unix := t.Unix()
+	days := math.Floor(float64(unix) / SEC_PER_DAY)
+	sec := float64(unix) - days*SEC_PER_DAY + float64(t.Nanosecond())*1e-9
+	jd1 := JD_UNIX + days
+	jd2 := sec / SEC_PER_DAY
+	return fromUTC(jd1, jd2)
+}
+
+func FromJD(jd float64, scale Scale) (Instant, error) {
+	return FromJD2(jd, 0., scale)
+}
+
+func FromMJD(mjd float64, scale Scale) (Instant, error) {
+	return FromJD2(MJD_OFFSET, mjd, scale)
+}
+
+// FromJD2 returns the instant of the two-part Julian date jd1+jd2 in scale.
+func FromJD2(jd1, jd2 float64, scale Scale) (Instant, error) {
+	t := newInstant(jd1, jd2)
+	switch scale {
+	case UTC, UT1:
+		// Without DUT1, UT1 is taken to be UTC.
+		return fromUTC(t.jd1, t.jd2), nil
+	case TAI:
+		return t, nil
+	case TT:
+		return newInstant(t.jd1, t.jd2-TT_MINUS_TAI/SEC_PER_DAY), nil
+	case TDB:
+		tt := newInstant(t.jd1, t.jd2-TT_MINUS_TAI/SEC_PER_DAY)
+		return tt.Add(-secondsToDuration(tdbMinusTT(tt.JD(TT)))), nil
+	}
+	return Instant{}, fmt.Errorf("%w %q", ErrUnknownScale, scale)
+}
+
+func fromUTC(jd1, jd2 float64) Instant {
+	t := newInstant(jd1, jd2)
+	return newInstant(t.jd1, t.jd2+TAIMinusUTC((t.jd1-MJD_OFFSET)+t.jd2)/SEC_PER_DAY)
+}
+
+// newInstant normalizes jd1 to a half-integer date and jd2 to a day fraction.
+func newInstant(jd1, jd2 float64) Instant {
+	d1 := math.Floor(jd1-0.5) + 0.5
+	jd2 += jd1 - d1
+	d2 := math.Floor(jd2)
+	return Instant{jd1: d1 + d2, jd2: jd2 - d2}
+}
+
+// WithDUT1 returns the same instant with UT1-UTC set to dut1 seconds.
+func (t Instant) WithDUT1(dut1 float64) Instant {
+	t.dut1 = dut1
+	return t
+}
+
+func (t Instant) DUT1() float64 {
+	return t.dut1
+}
+
+// JD2 returns the two-part Julian date of the instant in scale.
+// Both parts are NaN for an unknown scale.
+func (t Instant) JD2(scale Scale) (float64, float64) {
+	switch scale {
+	case TAI:
+		return t.jd1, t.jd2
+	case TT:
+		return t.jd1, t.jd2 + TT_MINUS_TAI/SEC_PER_DAY
+	case TDB:
+		jd1, jd2 := t.JD2(TT)
+		return jd1, jd2 + tdbMinusTT(jd1+jd2)/SEC_PER_DAY
+	case UTC:
+		return t.jd1, t.jd2 - t.taiMinusUTC()/SEC_PER_DAY
+	case UT1:
+		jd1, jd2 := t.JD2(UTC)
+		return jd1, jd2 + t.dut1/SEC_PER_DAY
+	}
+	return math.NaN(), math.NaN()
+}
+
+func (t Instant) JD(scale Scale) float64 {
+	jd1, jd2 := t.JD2(scale)
+	return jd1 + jd2
+}
+
+func (t Instant) MJD(scale Scale) float64 {
+	jd1, jd2 := t.JD2(scale)
+	return (jd1 - MJD_OFFSET) + jd2
+}
+
+// JulianEpoch returns the Julian epoch in TT, e.g. 2000.0 for J2000.
+func (t Instant) JulianEpoch() float64 {
+	jd1, jd2 := t.JD2(TT)
+	return 2000. + ((jd1-JD_J2000)+jd2)/365.25
+}
+
+// Time returns the instant as a time.Time in UTC.
+// Instants inside a leap second are mapped to the following second.
+func (t Instant) Time() time.Time {
+	jd1, jd2 := t.JD2(UTC)
+	days := math.Floor(jd1 - JD_UNIX)
+	sec := ((jd1 - JD_UNIX - days) + jd2) * SEC_PER_DAY
+	whole := math.Floor(sec)
+	nsec := math.Round((sec - whole) * 1e9)
+	return time.Unix(int64(days)*int64(SEC_PER_DAY)+int64(whole), int64(nsec)).UTC()
+}
+
+func (t Instant) String() string {
+	return t.Time().Format(`2006-01-02T15:04:05.000`) + ` UTC`
+}
+
+// Add returns the instant d (in SI seconds) after t.
+func (t Instant) Add(d time.Duration) Instant {
+	u := newInstant(t.jd1, t.jd2+d.Seconds()/SEC_PER_DAY)
+	u.dut1 = t.dut1
+	return u
+}
+
+// Sub returns the elapsed SI time t-u.
+func (t Instant) Sub(u Instant) time.Duration {
+	return secondsToDuration(((t.jd1 - u.jd1) + (t.jd2 - u.jd2)) * SEC_PER_DAY)
+}
+
+func (t Instant) Before(u Instant) bool {
+	return t.Sub(u) < 0
+}
+
+func (t Instant) After(u Instant) bool {
+	return t.Sub(u) > 0
+}
+
+func (t Instant) taiMinusUTC() float64 {
+	// TAI-UTC is looked up at UTC, which is first approximated with the value at TAI.
+	tai := t.MJD(TAI)
+	return TAIMinusUTC(tai - TAIMinusUTC(tai)/SEC_PER_DAY)
+}
+
+// tdbMinusTT returns TDB-TT in seconds at jd (TT), accurate to about 30 microseconds.
+func tdbMinusTT(jd float64) float64 {
+	g := (357.53 + 0.98560028*(jd-JD_J2000)) * math.Pi / 180.
+	return 0.001657*math.Sin(g) + 0.00001385*math.Sin(2.*g)
+}
+
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(math.Round(sec * 1e9))
+}
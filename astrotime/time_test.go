@@ -0,0 +1,55 @@
+package astrotime
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTAIMinusUTC(t *testing.T) {
+	cases := []struct{ mjd, dt float64 }{
+		{41317., 10.}, // 1972-01-01
+		{57753.9, 36.},
+		{57754., 37.}, // 2017-01-01
+	}
+	for _, c := range cases {
+		if dt := TAIMinusUTC(c.mjd); dt != c.dt {
+			t.Errorf("MJD %g: %g s", c.mjd, dt)
+		}
+	}
+}
+
+func TestJ2000(t *testing.T) {
+	// J2000.0 is 2000-01-01T12:00:00 TT = 11:58:55.816 UTC.
+	j2000 := FromTime(time.Date(2000, 1, 1, 11, 58, 55, 816000000, time.UTC))
+	if d := (j2000.JD(TT) - JD_J2000) * SEC_PER_DAY; math.Abs(d) > 1e-6 {
+		t.Errorf("off by %g s", d)
+	}
+	if e := j2000.JulianEpoch(); math.Abs(e-2000.) > 1e-12 {
+		t.Errorf("epoch %g", e)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	utc := time.Date(2024, 6, 30, 23, 59, 59, 123456000, time.UTC)
+	ti := FromTime(utc)
+	if d := ti.Time().Sub(utc); d < -time.Microsecond || d > time.Microsecond {
+		t.Errorf("time off by %s", d)
+	}
+	for _, scale := range []Scale{UTC, TAI, TT, TDB} {
+		jd1, jd2 := ti.JD2(scale)
+		u, err := FromJD2(jd1, jd2, scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := u.Sub(ti); d < -time.Microsecond || d > time.Microsecond {
+			t.Errorf("%s off by %s", scale, d)
+		}
+	}
+	if d := (ti.JD(TDB) - ti.JD(TT)) * SEC_PER_DAY; math.Abs(d) > 0.002 {
+		t.Errorf("TDB-TT = %g s", d)
+	}
+	if _, err := FromJD(2451545., `XYZ`); err == nil {
+		t.Errorf("no error for an unknown scale")
+	}
+}
@@ -0,0 +1,62 @@
+package coordinate
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	/* Airmass models */
+	AIRMASS_SECANT       string = `secant`       // plane-parallel atmosphere, 1/sin(el)
+	AIRMASS_KASTEN_YOUNG string = `kasten-young` // Kasten & Young (1989)
+	AIRMASS_PICKERING    string = `pickering`    // Pickering (2002)
+)
+
+/* Airmass and opacity */
+// Airmass returns the relative air mass at the elevation el [deg].
+// It is infinite below the horizon.
+func Airmass(el float64, model string) (float64, error) {
+	if el < 0 {
+		switch model {
+		case AIRMASS_SECANT, AIRMASS_KASTEN_YOUNG, AIRMASS_PICKERING:
+			return math.Inf(1), nil
+		}
+	}
+	switch model {
+	case AIRMASS_SECANT:
+		return 1. / math.Sin(DegToRad(el)), nil
+	case AIRMASS_KASTEN_YOUNG:
+		return 1. / (math.Sin(DegToRad(el)) + 0.50572*math.Pow(el+6.07995, -1.6364)), nil
+	case AIRMASS_PICKERING:
+		return 1. / math.Sin(DegToRad(el+244./(165.+47.*math.Pow(el, 1.1)))), nil
+	}
+	return 0., fmt.Errorf("unknown airmass model %q", model)
+}
+
+// LineOfSightOpacity returns the opacity toward the elevation el [deg] for the zenith opacity tau.
+func LineOfSightOpacity(tau, el float64, model string) (float64, error) {
+	x, err := Airmass(el, model)
+	if err != nil {
+		return 0., err
+	}
+	return tau * x, nil
+}
+
+// Transmission returns the fraction exp(-tau*X) of the signal passing through the atmosphere.
+func Transmission(tau, el float64, model string) (float64, error) {
+	t, err := LineOfSightOpacity(tau, el, model)
+	if err != nil {
+		return 0., err
+	}
+	return math.Exp(-t), nil
+}
+
+// SkyTemperature returns the brightness temperature of the atmosphere [K] of the physical
+// temperature tatm [K] toward the elevation el [deg], i.e. tatm*(1-exp(-tau*X)).
+func SkyTemperature(tau, el, tatm float64, model string) (float64, error) {
+	tr, err := Transmission(tau, el, model)
+	if err != nil {
+		return 0., err
+	}
+	return tatm * (1. - tr), nil
+}
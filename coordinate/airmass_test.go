@@ -0,0 +1,35 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAirmass(t *testing.T) {
+	cases := []struct {
+		model string
+		el, x float64
+	}{
+		{AIRMASS_SECANT, 30., 2.},
+		{AIRMASS_SECANT, 90., 1.},
+		{AIRMASS_KASTEN_YOUNG, 90., 1.},
+		{AIRMASS_KASTEN_YOUNG, 0., 37.92},
+		{AIRMASS_PICKERING, 90., 1.},
+		{AIRMASS_PICKERING, 0., 38.75},
+	}
+	for _, c := range cases {
+		x, err := Airmass(c.el, c.model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(x-c.x) > 0.01 {
+			t.Errorf("%s at %g deg = %g", c.model, c.el, x)
+		}
+	}
+	if x, _ := Airmass(-1., AIRMASS_SECANT); !math.IsInf(x, 1) {
+		t.Errorf("below the horizon = %g", x)
+	}
+	if _, err := Airmass(30., `unknown`); err == nil {
+		t.Errorf("no error for an unknown model")
+	}
+}
@@ -0,0 +1,88 @@
+package coordinate
+
+import (
+	"math"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+const (
+	SCHWARZSCHILD_RADIUS_SUN float64 = 1.97412574336e-8 // AU
+	EARTH_ROTATION_RATE      float64 = 7.292115e-5      // rad/s
+)
+
+/* Apparent place */
+// ApparentPlace returns the apparent place (true equator and equinox of date) of c,
+// an astrometric (catalog) direction, seen from o at t. Light deflection by the Sun
+// and annual and diurnal aberration are applied. The geocenter is used if o is nil.
+// Without an ephemeris (see SetEphemeris), the Earth follows VSOP87, whose velocity
+// is accurate to about 1 m/s, or 1 mas in the aberration.
+func ApparentPlace(c Coordinate, o Observatory, t astrotime.Instant) (Coordinate, error) {
+	icrs, err := c.Convert(SYSTEM_ICRS)
+	if err != nil {
+		return nil, err
+	}
+	jd := t.JD(astrotime.TT)
+	v := trueOfDateMatrix(jd).Apply(astrometricToApparent(icrs.ToCartesian(), o, t))
+	return CoordinateOfSphere(TrueOfDate(jd, PRECESSION_NUTATION_FULL), v.ToSpherical().Normalize())
+}
+
+// astrometricToApparent applies light deflection and aberration to the ICRS direction p.
+func astrometricToApparent(p *Cartesian, o Observatory, t astrotime.Instant) *Cartesian {
+	pos, vel := observerPositionVelocity(o, t)
+	em := pos.Norm()
+	p = lightDeflection(p.Unit(), pos.Scale(1./em), em)
+	return aberration(p, vel.Scale(LIGHT_TIME_AU_DAY), em)
+}
+
+// apparentToAstrometric is the inverse of astrometricToApparent, solved by iteration.
+func apparentToAstrometric(v *Cartesian, o Observatory, t astrotime.Instant) *Cartesian {
+	pos, vel := observerPositionVelocity(o, t)
+	em := pos.Norm()
+	e := pos.Scale(1. / em)
+	beta := vel.Scale(LIGHT_TIME_AU_DAY)
+	v = v.Unit()
+	p := v
+	for i := 0; i < 5; i++ {
+		p = p.Add(v.Sub(aberration(lightDeflection(p, e, em), beta, em))).Unit()
+	}
+	return p
+}
+
+// observerPositionVelocity returns the heliocentric position [AU] and the barycentric velocity
+// [AU/day] of the observer in ICRS at t, or those of the geocenter if o is nil.
+func observerPositionVelocity(o Observatory, t astrotime.Instant) (*Cartesian, *Cartesian) {
+	pos, vel := earthPositionVelocity(t.JD(astrotime.TDB))
+	if o == nil {
+		return pos, vel
+	}
+	r, v := observatoryGCRS(o, t)
+	return pos.Add(r.Scale(1. / AU_METER)), vel.Add(v.Scale(SECONDS_PER_DAY / AU_METER))
+}
+
+// observatoryGCRS returns the geocentric position [m] and velocity [m/s] of o in ICRS axes at t.
+// Polar motion is ignored.
+func observatoryGCRS(o Observatory, t astrotime.Instant) (*Cartesian, *Cartesian) {
+	r := RotationZ(-GAST(t).Radian()).Apply(o.ITRS())
+	v := &Cartesian{X: -EARTH_ROTATION_RATE * r.Y, Y: EARTH_ROTATION_RATE * r.X, Z: 0.}
+	m := trueOfDateMatrix(t.JD(astrotime.TT)).Transpose()
+	return m.Apply(r), m.Apply(v)
+}
+
+// lightDeflection deflects the direction p of a distant source by the Sun,
+// for an observer at the heliocentric direction e and distance em [AU].
+func lightDeflection(p, e *Cartesian, em float64) *Cartesian {
+	dlim := 1e-6 / math.Max(em*em, 1.)
+	w := SCHWARZSCHILD_RADIUS_SUN / em / math.Max(p.Dot(p.Add(e)), dlim)
+	return p.Add(p.Cross(e.Cross(p)).Scale(w))
+}
+
+// aberration applies the relativistic aberration for an observer moving with
+// velocity beta (in units of c) at the distance em [AU] from the Sun.
+func aberration(p, beta *Cartesian, em float64) *Cartesian {
+	bm1 := math.Sqrt(1. - beta.Dot(beta))
+	pdv := p.Dot(beta)
+	w1 := 1. + pdv/(1.+bm1)
+	w2 := SCHWARZSCHILD_RADIUS_SUN / em
+	return p.Scale(bm1).Add(beta.Scale(w1)).Add(beta.Sub(p.Scale(pdv)).Scale(w2)).Unit()
+}
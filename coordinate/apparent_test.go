@@ -0,0 +1,15 @@
+package coordinate
+
+import (
+	"testing"
+)
+
+// SOFA iauAb test case
+func TestAberration(t *testing.T) {
+	pnat := &Cartesian{X: -0.76321968546737951, Y: -0.60869453983060384, Z: -0.21676408580639883}
+	v := &Cartesian{X: 2.1044018893653786e-5, Y: -8.9108923304429319e-5, Z: -3.8633714797716569e-5}
+	want := &Cartesian{X: -0.7631631094219556269, Y: -0.6087553082505590832, Z: -0.2167926269368471279}
+	if d := aberration(pnat, v, 0.99980921395708788).Sub(want).Norm(); d > 1e-12 {
+		t.Errorf("off by %g", d)
+	}
+}
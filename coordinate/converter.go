@@ -1,46 +1,301 @@
 package coordinate
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 const (
 	BEPOCH_WEIGHT float64 = (15019.81352 + (1950.-1900.)*365.242198781 - 51544.5) / 365.25 / (100. * 60. * 60. * 360. / 2.)
 )
 
-func J2000ToGal(c Coordinate) Coordinate {
-	RMAT := [][]float64{
+/* Built-in frames */
+func init() {
+	j2000 := NewRotationFrame(SYSTEM_J2000, SYSTEM_ICRS, BIAS_MATRIX, SYSTEM_FK5)
+	j2000.Wrap = func(c Coordinate) Coordinate { return &J2000{c.(*coordinate)} }
+	mustRegisterFrame(j2000)
+	mustRegisterFrame(&Frame{
+		Name:       SYSTEM_B1950,
+		Aliases:    []string{SYSTEM_FK4},
+		Parent:     SYSTEM_J2000,
+		ToParent:   fk4ToFK5,
+		FromParent: fk5ToFK4,
+		Wrap:       func(c Coordinate) Coordinate { return &B1950{c.(*coordinate)} },
+	})
+	gal := NewRotationFrame(SYSTEM_GAL, SYSTEM_J2000, GAL_MATRIX, `Galactic`)
+	gal.Wrap = func(c Coordinate) Coordinate { return &Gal{c.(*coordinate)} }
+	mustRegisterFrame(gal)
+
+	supergal := NewRotationFrame(SYSTEM_SUPERGAL, SYSTEM_GAL, SUPERGAL_MATRIX, `Supergalactic`)
+	supergal.Wrap = func(c Coordinate) Coordinate { return &SuperGal{c.(*coordinate)} }
+	mustRegisterFrame(supergal)
+
+	ecliptic := NewRotationFrame(SYSTEM_ECLIPTIC, SYSTEM_J2000, RotationX(MeanObliquity(JD_J2000)), `EclipticJ2000`)
+	ecliptic.Wrap = wrapEcliptic
+	mustRegisterFrame(ecliptic)
+	for name, family := range map[string]FrameFamily{
+		SYSTEM_ECLIPTIC_OF_DATE: eclipticOfDate,
+		SYSTEM_FK5:              fk5Family,
+		SYSTEM_FK4:              fk4Family,
+		SYSTEM_MEAN_OF_DATE:     meanOfDateFamily,
+		SYSTEM_TRUE_OF_DATE:     trueOfDateFamily,
+		SYSTEM_OFFSET:           offsetFrame,
+	} {
+		if err := RegisterFrameFamily(name, family); err != nil {
+			panic(err)
+		}
+	}
+}
+
+var (
+	// Frame bias from ICRS to FK5 J2000 (IERS Conventions 2003)
+	BIAS_MATRIX = RotationX(-ArcsecToRad(-0.0068192)).Multiply(RotationY(ArcsecToRad(-0.0166170))).Multiply(RotationZ(ArcsecToRad(-0.0146)))
+
+	// Elliptic terms of aberration in FK4 B1950
+	FK4_ETERMS = &Cartesian{X: -1.62557e-6, Y: -0.31919e-6, Z: -0.13843e-6}
+
+	// Rotation from equatorial (FK5 J2000) to galactic coordinates
+	GAL_MATRIX = Matrix{
 		{-0.054875539726, -0.873437108010, -0.483834985808},
 		{+0.494109453312, -0.444829589425, +0.746982251810},
 		{-0.867666135858, -0.198076386122, +0.455983795705},
 	}
-	c1 := c.ToCartesian()
-	c2 := &Cartesian{
-		X: RMAT[0][0]*c1.X + RMAT[0][1]*c1.Y + RMAT[0][2]*c1.Z,
-		Y: RMAT[1][0]*c1.X + RMAT[1][1]*c1.Y + RMAT[1][2]*c1.Z,
-		Z: RMAT[2][0]*c1.X + RMAT[2][1]*c1.Y + RMAT[2][2]*c1.Z,
+
+	// Rotation from galactic to supergalactic coordinates (de Vaucouleurs et al. 1991).
+	// The supergalactic pole is at (l, b) = (47.37, +6.32) deg, and the origin at (137.37, 0) deg.
+	SUPERGAL_MATRIX = axesMatrix(
+		(&Spherical{X: NewAngle(137.37), Y: NewAngle(0.)}).ToCartesian(),
+		(&Spherical{X: NewAngle(47.37), Y: NewAngle(6.32)}).ToCartesian(),
+	)
+)
+
+// axesMatrix returns the rotation into the frame whose x and z axes are x and z.
+func axesMatrix(x, z *Cartesian) Matrix {
+	y := &Cartesian{
+		X: z.Y*x.Z - z.Z*x.Y,
+		Y: z.Z*x.X - z.X*x.Z,
+		Z: z.X*x.Y - z.Y*x.X,
 	}
-	s := c2.ToSpherical().ToGal()
-	return NewCoordinateFromSphere(`Gal`, s)
+	return Matrix{{x.X, x.Y, x.Z}, {y.X, y.Y, y.Z}, {z.X, z.Y, z.Z}}
 }
 
-func GalToJ2000(c Coordinate) Coordinate {
-	RMAT := [][]float64{
-		{-0.054875539726, -0.873437108010, -0.483834985808},
-		{+0.494109453312, -0.444829589425, +0.746982251810},
-		{-0.867666135858, -0.198076386122, +0.455983795705},
+// EclipticOfDate returns the system name of the mean ecliptic and equinox of jd (TT).
+func EclipticOfDate(jd float64) string {
+	return fmt.Sprintf(`%s(%s)`, SYSTEM_ECLIPTIC_OF_DATE, FormatJulianEpoch(jd))
+}
+
+func eclipticOfDate(args []string) (*Frame, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected one equinox, e.g. %s(J2020.5)", SYSTEM_ECLIPTIC_OF_DATE)
+	}
+	jd, err := ParseEpoch(args[0])
+	if err != nil {
+		return nil, err
+	}
+	m := RotationX(MeanObliquity(jd)).Multiply(PrecessionMatrix(jd))
+	f := NewRotationFrame(EclipticOfDate(jd), SYSTEM_J2000, m)
+	f.Wrap = wrapEcliptic
+	return f, nil
+}
+
+func wrapEcliptic(c Coordinate) Coordinate {
+	return &Ecliptic{c.(*coordinate)}
+}
+
+/* FK5 and FK4 of arbitrary equinox */
+// FK5System returns the system name of FK5 with the equinox jd (TT), e.g. "FK5(J2010)".
+func FK5System(equinox float64) string {
+	if equinox == JD_J2000 {
+		return SYSTEM_J2000
+	}
+	return fmt.Sprintf(`%s(%s)`, SYSTEM_FK5, FormatJulianEpoch(equinox))
+}
+
+// FK4System returns the system name of FK4 with the equinox and the epoch of observation,
+// e.g. "FK4(B1950,B1983.5)".
+func FK4System(equinox, epoch float64) string {
+	b1950 := BesselianEpochToJD(1950.)
+	if equinox == b1950 && epoch == b1950 {
+		return SYSTEM_B1950
+	}
+	return fmt.Sprintf(`%s(%s,%s)`, SYSTEM_FK4, FormatBesselianEpoch(equinox), FormatBesselianEpoch(epoch))
+}
+
+func fk5Family(args []string) (*Frame, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected one equinox, e.g. %s(J2010)", SYSTEM_FK5)
+	}
+	equinox, err := ParseEpoch(args[0])
+	if err != nil {
+		return nil, err
+	}
+	f := NewRotationFrame(FK5System(equinox), SYSTEM_J2000, PrecessionMatrixIAU1976(equinox))
+	f.Wrap = func(c Coordinate) Coordinate { return &FK5{coordinate: c.(*coordinate), Equinox: equinox} }
+	return f, nil
+}
+
+func fk4Family(args []string) (*Frame, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("expected an equinox and an optional epoch, e.g. %s(B1950,B1983.5)", SYSTEM_FK4)
+	}
+	equinox, err := parseBesselianEpoch(args[0])
+	if err != nil {
+		return nil, err
+	}
+	epoch := equinox
+	if len(args) == 2 {
+		if epoch, err = parseBesselianEpoch(args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	b1950 := BesselianEpochToJD(1950.)
+	toB1950 := PrecessionMatrixNewcomb(equinox, b1950)
+	fromB1950 := toB1950.Transpose()
+	eterms := fk4ETerms(equinox)
+	return &Frame{
+		Name:   FK4System(equinox, epoch),
+		Parent: SYSTEM_J2000,
+		ToParent: func(v *Cartesian) *Cartesian {
+			v = addETerms(toB1950.Apply(removeETerms(v, eterms)), FK4_ETERMS)
+			return fk4ToFK5Epoch(v, epoch)
+		},
+		FromParent: func(v *Cartesian) *Cartesian {
+			v = fromB1950.Apply(removeETerms(fk5ToFK4Epoch(v, epoch), FK4_ETERMS))
+			return addETerms(v, eterms)
+		},
+		Wrap: func(c Coordinate) Coordinate {
+			return &FK4{coordinate: c.(*coordinate), Equinox: equinox, Epoch: epoch}
+		},
+	}, nil
+}
+
+/* Equator and equinox of date */
+// MeanOfDate returns the system name of the mean equator and equinox of jd (TT).
+func MeanOfDate(jd float64) string {
+	return fmt.Sprintf(`%s(%s)`, SYSTEM_MEAN_OF_DATE, formatJD(jd))
+}
+
+// TrueOfDate returns the system name of the true equator and equinox of jd (TT),
+// i.e. the apparent RA/Dec of date without aberration and light deflection.
+// model is PRECESSION_NUTATION_FULL or PRECESSION_NUTATION_FAST.
+func TrueOfDate(jd float64, model string) string {
+	if model == PRECESSION_NUTATION_FULL {
+		return fmt.Sprintf(`%s(%s)`, SYSTEM_TRUE_OF_DATE, formatJD(jd))
+	}
+	return fmt.Sprintf(`%s(%s,%s)`, SYSTEM_TRUE_OF_DATE, formatJD(jd), model)
+}
+
+func meanOfDateFamily(args []string) (*Frame, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected one equinox, e.g. %s(J2020.5)", SYSTEM_MEAN_OF_DATE)
+	}
+	jd, err := ParseEpoch(args[0])
+	if err != nil {
+		return nil, err
+	}
+	f := NewRotationFrame(MeanOfDate(jd), SYSTEM_J2000, PrecessionMatrix(jd))
+	f.Wrap = func(c Coordinate) Coordinate { return &EquatorialOfDate{coordinate: c.(*coordinate), Equinox: jd} }
+	return f, nil
+}
+
+func trueOfDateFamily(args []string) (*Frame, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("expected an equinox and an optional model, e.g. %s(J2020.5,%s)", SYSTEM_TRUE_OF_DATE, PRECESSION_NUTATION_FAST)
+	}
+	jd, err := ParseEpoch(args[0])
+	if err != nil {
+		return nil, err
+	}
+	model := PRECESSION_NUTATION_FULL
+	if len(args) == 2 {
+		model = strings.ToLower(args[1])
+	}
+	m, err := PrecessionNutationMatrix(jd, model)
+	if err != nil {
+		return nil, err
+	}
+	f := NewRotationFrame(TrueOfDate(jd, model), SYSTEM_J2000, m)
+	f.Wrap = func(c Coordinate) Coordinate { return &EquatorialOfDate{coordinate: c.(*coordinate), Equinox: jd} }
+	return f, nil
+}
+
+// parseBesselianEpoch is like ParseEpoch, but takes plain numbers as Besselian epochs.
+func parseBesselianEpoch(epoch string) (float64, error) {
+	if _, err := strconv.ParseFloat(epoch, 64); err == nil {
+		epoch = `B` + epoch
+	}
+	return ParseEpoch(epoch)
+}
+
+// fk4ETerms returns the elliptic terms of aberration for the FK4 equinox jd.
+func fk4ETerms(jd float64) *Cartesian {
+	t := julianCenturies(jd)
+	k := DegToRad(0.0056932)
+	e := 0.016708634 - t*(0.000042037+t*0.0000001267)
+	g := DegToRad(102.93735 + t*(1.71946+t*0.00046))
+	o := DegToRad(23.4392911111 - t*(0.0130041667+t*(0.0000001639-t*0.0000005036)))
+	return &Cartesian{
+		X: -e * k * math.Sin(g),
+		Y: e * k * math.Cos(g) * math.Cos(o),
+		Z: e * k * math.Cos(g) * math.Sin(o),
+	}
+}
+
+func removeETerms(v, a *Cartesian) *Cartesian {
+	w := v.X*a.X + v.Y*a.Y + v.Z*a.Z
+	return &Cartesian{
+		X: (1.+w)*v.X - a.X,
+		Y: (1.+w)*v.Y - a.Y,
+		Z: (1.+w)*v.Z - a.Z,
 	}
-	c1 := c.ToCartesian()
-	c2 := &Cartesian{
-		X: RMAT[0][0]*c1.X + RMAT[1][0]*c1.Y + RMAT[2][0]*c1.Z,
-		Y: RMAT[0][1]*c1.X + RMAT[1][1]*c1.Y + RMAT[2][1]*c1.Z,
-		Z: RMAT[0][2]*c1.X + RMAT[1][2]*c1.Y + RMAT[2][2]*c1.Z,
+}
+
+func addETerms(v, a *Cartesian) *Cartesian {
+	r := math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+	w := v.X*a.X + v.Y*a.Y + v.Z*a.Z
+	return &Cartesian{
+		X: (1.-w)*v.X + a.X*r,
+		Y: (1.-w)*v.Y + a.Y*r,
+		Z: (1.-w)*v.Z + a.Z*r,
 	}
-	s := c2.ToSpherical().ToEq()
-	return NewCoordinateFromSphere(`J2000`, s)
+}
+
+func identity(v *Cartesian) *Cartesian {
+	return &Cartesian{X: v.X, Y: v.Y, Z: v.Z}
+}
+
+func J2000ToGal(c Coordinate) Coordinate {
+	s := GAL_MATRIX.Apply(c.ToCartesian()).ToSpherical().ToGal()
+	return NewCoordinateFromSphere(SYSTEM_GAL, s)
+}
+
+func GalToJ2000(c Coordinate) Coordinate {
+	s := GAL_MATRIX.Transpose().Apply(c.ToCartesian()).ToSpherical().ToEq()
+	return NewCoordinateFromSphere(SYSTEM_J2000, s)
 }
 
 func J2000ToB1950(c Coordinate) Coordinate {
+	s := fk5ToFK4(c.ToCartesian()).ToSpherical().ToEq()
+	return NewCoordinateFromSphere(SYSTEM_B1950, s)
+}
+
+func B1950ToJ2000(c Coordinate) Coordinate {
+	s := fk4ToFK5(c.ToCartesian()).ToSpherical().ToEq()
+	return NewCoordinateFromSphere(SYSTEM_J2000, s)
+}
+
+func B1950ToGal(c Coordinate) Coordinate {
+	return J2000ToGal(B1950ToJ2000(c))
+}
+
+func GalToB1950(c Coordinate) Coordinate {
+	return J2000ToB1950(GalToJ2000(c))
+}
+
+func fk5ToFK4(c1 *Cartesian) *Cartesian {
 	A := []float64{-1.62557e-6, -0.31919e-6, -0.13843e-6, 1.245e-3, -1.580e-3, -0.659e-3}
 	EMI := [][]float64{
 		{+0.9999256795, -0.0111814828, -0.0048590040, -0.000551, -0.238560, +0.435730},
@@ -48,7 +303,6 @@ func J2000ToB1950(c Coordinate) Coordinate {
 		{+0.0048590039, -0.0000271771, +0.9999881946, -0.435614, +0.012254, +0.002117},
 	}
 
-	c1 := c.ToCartesian()
 	x := c1.X
 	y := c1.Y
 	z := c1.Z
@@ -76,56 +330,107 @@ func J2000ToB1950(c Coordinate) Coordinate {
 	y = (1.-w)*v2[1] + A[1]*rxyz
 	z = (1.-w)*v2[2] + A[2]*rxyz
 
-	rxy := math.Sqrt(x*x + y*y)
+	return &Cartesian{X: x, Y: y, Z: z}
+}
 
-	var ra, dec float64
-	if x == 0 && y == 0 {
-		ra = 0.
-	} else {
-		ra = math.Atan2(y, x)
-		if ra < 0 {
-			ra += math.Pi * 2.
-		}
-	}
-	dec = math.Atan2(z, rxy)
+func fk4ToFK5(c1 *Cartesian) *Cartesian {
+	return fk4ToFK5Epoch(c1, BesselianEpochToJD(1950.))
+}
 
-	return NewCoordinate(`B1950`, RadToDeg(ra), RadToDeg(dec))
+// fk4ToFK5Epoch converts the FK4 B1950 vector observed at epoch (JD) to FK5 J2000,
+// assuming zero proper motion in FK5.
+func fk4ToFK5Epoch(c1 *Cartesian, epoch float64) *Cartesian {
+	return fk4ToFK5Matrix(epoch).Apply(removeETerms(c1, FK4_ETERMS))
 }
 
-func B1950ToJ2000(c Coordinate) Coordinate {
-	A := []float64{-1.62557e-6, -0.31919e-6, -0.13843e-6}
+// fk5ToFK4Epoch is the inverse of fk4ToFK5Epoch.
+func fk5ToFK4Epoch(c1 *Cartesian, epoch float64) *Cartesian {
+	return addETerms(fk4ToFK5Matrix(epoch).Inverse().Apply(c1), FK4_ETERMS)
+}
+
+// fk4ToFK5Matrix returns the rotation from FK4 B1950 without E-terms to FK5 J2000,
+// including the fictitious proper motion accumulated between J2000 and epoch (JD).
+func fk4ToFK5Matrix(epoch float64) Matrix {
 	EM := [][]float64{
 		{+0.9999256782, +0.0111820610, +0.0048579479, -0.000551, +0.238514, -0.435623},
 		{-0.0111820611, +0.9999374784, -0.0000271474, -0.238565, -0.002667, +0.012254},
 		{-0.0048579477, -0.0000271765, +0.9999881997, +0.435739, -0.008541, +0.002117},
 	}
 
-	c1 := c.ToCartesian()
-	r0 := []float64{c1.X, c1.Y, c1.Z}
-	w := r0[0]*A[0] + r0[1]*A[1] + r0[2]*A[2]
-
-	for i := 0; i < 3; i++ {
-		r0[i] = (1.+w)*r0[i] - A[i]
-	}
+	weight := (epoch - JD_J2000) / DAYS_PER_JULIAN_YEAR / (100. * 60. * 60. * 360. / 2.)
 
-	v2 := []float64{0., 0., 0., 0., 0., 0.}
+	var m Matrix
 	for i := 0; i < 3; i++ {
 		for j := 0; j < 3; j++ {
-			v2[i] += EM[j][i] * r0[j]
-			v2[i+3] += EM[j][i+3] * r0[j]
+			m[i][j] = EM[j][i] + math.Pi*weight*EM[j][i+3]
 		}
-		v2[i] += math.Pi * BEPOCH_WEIGHT * v2[i+3]
 	}
+	return m
+}
+
+// Position-velocity matrices between FK4 B1950 without E-terms and FK5 J2000
+// (Standish 1982, as SOFA fk425 and fk524), indexed by [out pv][axis][in pv][axis].
+// Velocities are in arcsec per century for unit vectors.
+var (
+	FK4_ETERMS_RATE = &Cartesian{X: 1.245e-3, Y: -1.580e-3, Z: -0.659e-3}
 
-	c2 := &Cartesian{X: v2[0], Y: v2[1], Z: v2[2]}
-	s := c2.ToSpherical().ToEq()
-	return NewCoordinateFromSphere(`J2000`, s)
+	fk4ToFK5PV = [2][3][2][3]float64{
+		{
+			{{+0.9999256782, -0.0111820610, -0.0048579479}, {+0.00000242395018, -0.00000002710663, -0.00000001177656}},
+			{{+0.0111820610, +0.9999374784, -0.0000271765}, {+0.00000002710663, +0.00000242397878, -0.00000000006582}},
+			{{+0.0048579479, -0.0000271474, +0.9999881997}, {+0.00000001177656, -0.00000000006587, +0.00000242410173}},
+		},
+		{
+			{{-0.000551, -0.238565, +0.435739}, {+0.99994704, -0.01118251, -0.00485767}},
+			{{+0.238514, -0.002667, -0.008541}, {+0.01118251, +0.99995883, -0.00002718}},
+			{{-0.435623, +0.012254, +0.002117}, {+0.00485767, -0.00002714, +1.00000956}},
+		},
+	}
+	fk5ToFK4PV = [2][3][2][3]float64{
+		{
+			{{+0.9999256795, +0.0111814828, +0.0048590039}, {-0.00000242389840, -0.00000002710544, -0.00000001177742}},
+			{{-0.0111814828, +0.9999374849, -0.0000271771}, {+0.00000002710544, -0.00000242392702, +0.00000000006585}},
+			{{-0.0048590040, -0.0000271557, +0.9999881946}, {+0.00000001177742, +0.00000000006585, -0.00000242404995}},
+		},
+		{
+			{{-0.000551, +0.238509, -0.435614}, {+0.99990432, +0.01118145, +0.00485852}},
+			{{-0.238560, -0.002667, +0.012254}, {-0.01118145, +0.99991613, -0.00002717}},
+			{{+0.435730, -0.008541, +0.002117}, {-0.00485852, -0.00002716, +0.99996684}},
+		},
+	}
+)
+
+// fk4ToFK5PosVel converts the FK4 B1950 position-velocity at B1950 into FK5 J2000 at J2000.
+func fk4ToFK5PosVel(pos, vel *Cartesian) (*Cartesian, *Cartesian) {
+	// Remove the E-terms and their rate
+	p := pos.Sub(FK4_ETERMS).Add(pos.Scale(pos.Dot(FK4_ETERMS)))
+	v := vel.Sub(FK4_ETERMS_RATE).Add(pos.Scale(pos.Dot(FK4_ETERMS_RATE)))
+	return applyPV(fk4ToFK5PV, p, v)
 }
 
-func B1950ToGal(c Coordinate) Coordinate {
-	return J2000ToGal(B1950ToJ2000(c))
+// fk5ToFK4PosVel is the inverse of fk4ToFK5PosVel.
+func fk5ToFK4PosVel(pos, vel *Cartesian) (*Cartesian, *Cartesian) {
+	p, v := applyPV(fk5ToFK4PV, pos, vel)
+	// Add the E-terms, iterating once for the length
+	r := p.Norm()
+	q := p.Add(FK4_ETERMS.Scale(r).Sub(p.Scale(p.Dot(FK4_ETERMS))))
+	r = q.Norm()
+	q = p.Add(FK4_ETERMS.Scale(r).Sub(p.Scale(p.Dot(FK4_ETERMS))))
+	v = v.Add(FK4_ETERMS_RATE.Scale(r).Sub(q.Scale(p.Dot(FK4_ETERMS_RATE))))
+	return q, v
 }
 
-func GalToB1950(c Coordinate) Coordinate {
-	return J2000ToB1950(GalToJ2000(c))
+func applyPV(m [2][3][2][3]float64, pos, vel *Cartesian) (*Cartesian, *Cartesian) {
+	in := [2][3]float64{{pos.X, pos.Y, pos.Z}, {vel.X, vel.Y, vel.Z}}
+	var out [2][3]float64
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 2; k++ {
+				for l := 0; l < 3; l++ {
+					out[i][j] += m[i][j][k][l] * in[k][l]
+				}
+			}
+		}
+	}
+	return &Cartesian{X: out[0][0], Y: out[0][1], Z: out[0][2]}, &Cartesian{X: out[1][0], Y: out[1][1], Z: out[1][2]}
 }
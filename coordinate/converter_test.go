@@ -0,0 +1,54 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+// separationArcsec returns the angle between a and b in arcsec, converting b into the system of a.
+func separationArcsec(t *testing.T, a, b Coordinate) float64 {
+	b, err := b.Convert(a.System())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, v := a.ToCartesian(), b.ToCartesian()
+	cx, cy, cz := u.Y*v.Z-u.Z*v.Y, u.Z*v.X-u.X*v.Z, u.X*v.Y-u.Y*v.X
+	dot := u.X*v.X + u.Y*v.Y + u.Z*v.Z
+	return RadToDeg(math.Atan2(math.Sqrt(cx*cx+cy*cy+cz*cz), dot)) * 3600.
+}
+
+func TestGalactic(t *testing.T) {
+	// The Galactic center and the north Galactic pole in FK5 J2000
+	cases := []struct{ l, b, ra, dec float64 }{
+		{0., 0., 266.404996, -28.936172},
+		{0., 90., 192.859480, 27.128251},
+	}
+	for _, c := range cases {
+		gal := NewCoordinate(SYSTEM_GAL, c.l, c.b)
+		want := NewCoordinate(SYSTEM_J2000, c.ra, c.dec)
+		if sep := separationArcsec(t, want, gal); sep > 0.01 {
+			t.Errorf("(%g, %g) off by %g arcsec", c.l, c.b, sep)
+		}
+		if sep := separationArcsec(t, J2000ToGal(want), gal); sep > 0.01 {
+			t.Errorf("J2000ToGal (%g, %g) off by %g arcsec", c.l, c.b, sep)
+		}
+	}
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	c := NewCoordinate(SYSTEM_ICRS, 83.633, 22.014)
+	systems := []string{
+		SYSTEM_J2000, SYSTEM_B1950, SYSTEM_GAL, SYSTEM_SUPERGAL, SYSTEM_ECLIPTIC,
+		FK5System(JulianEpochToJD(1975.)), FK4System(BesselianEpochToJD(1900.), BesselianEpochToJD(1950.)),
+		MeanOfDate(2460000.5), TrueOfDate(2460000.5, PRECESSION_NUTATION_FULL),
+	}
+	for _, system := range systems {
+		d, err := c.Convert(system)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sep := separationArcsec(t, c, d); sep > 1e-4 {
+			t.Errorf("%s: off by %g arcsec", system, sep)
+		}
+	}
+}
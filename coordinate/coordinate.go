@@ -6,6 +6,22 @@ import (
 	"math"
 )
 
+const (
+	SYSTEM_J2000 string = `J2000`
+	SYSTEM_B1950 string = `B1950`
+	SYSTEM_GAL   string = `Gal`
+
+	SYSTEM_FK5 string = `FK5`
+	SYSTEM_FK4 string = `FK4`
+
+	SYSTEM_MEAN_OF_DATE string = `MeanOfDate`
+	SYSTEM_TRUE_OF_DATE string = `TrueOfDate`
+
+	SYSTEM_SUPERGAL         string = `SuperGal`
+	SYSTEM_ECLIPTIC         string = `Ecliptic`
+	SYSTEM_ECLIPTIC_OF_DATE string = `EclipticOfDate`
+)
+
 /* Struct Angle */
 type Angle struct {
 	deg float64
@@ -103,7 +119,7 @@ func (ang *Angle) String(format string) string {
 		h, m, s := ang.HMS()
 		return fmt.Sprintf("%02.0fh%02.0fm%.8fs", h, m, s)
 	default:
-		log.Printf("Unknown format %s. Return degree.")
+		log.Printf("Unknown format %s. Return degree.", format)
 		return fmt.Sprintf("%.8fd", ang.Degree())
 	}
 }
@@ -114,54 +130,34 @@ type Spherical struct {
 	Y *Angle
 }
 
-func (s *Spherical) Offset(xoff, yoff *Angle) *Spherical {
-	x := *s.X
-	y := *s.Y
-	y.Add(yoff)
-	xoff.deg /= math.Abs(math.Cos(y.Radian()))
-	x.Add(xoff)
-	return &Spherical{X: &x, Y: &y}
-}
-
-func (s *Spherical) ToEq() *Spherical {
+// Normalize returns the same direction with the longitude in [0, 360) deg.
+func (s *Spherical) Normalize() *Spherical {
 	x := s.X.Radian()
 	y := s.Y.Radian()
 
-	ra := math.Mod(x, 2.*math.Pi)
-	if ra < 0 {
-		ra += 2. * math.Pi
+	lon := math.Mod(x, 2.*math.Pi)
+	if lon < 0 {
+		lon += 2. * math.Pi
 	}
 
-	dec := math.Mod(y, 2.*math.Pi)
-	if math.Abs(dec) > math.Pi {
+	lat := math.Mod(y, 2.*math.Pi)
+	if math.Abs(lat) > math.Pi {
 		if y > 0 {
-			dec -= math.Pi
+			lat -= math.Pi
 		}
 		if y < 0 {
-			dec += math.Pi
+			lat += math.Pi
 		}
 	}
-	return &Spherical{X: NewAngle(RadToDeg(ra)), Y: NewAngle(RadToDeg(dec))}
+	return &Spherical{X: NewAngle(RadToDeg(lon)), Y: NewAngle(RadToDeg(lat))}
 }
 
-func (s *Spherical) ToGal() *Spherical {
-	x := s.X.Radian()
-	y := s.Y.Radian()
+func (s *Spherical) ToEq() *Spherical {
+	return s.Normalize()
+}
 
-	l := math.Mod(x, 2.*math.Pi)
-	if l < 0 {
-		l += 2. * math.Pi
-	}
-	b := math.Mod(y, 2.*math.Pi)
-	if math.Abs(b) > math.Pi {
-		if y > 0 {
-			b -= math.Pi
-		}
-		if y < 0 {
-			b += math.Pi
-		}
-	}
-	return &Spherical{X: NewAngle(RadToDeg(l)), Y: NewAngle(RadToDeg(b))}
+func (s *Spherical) ToGal() *Spherical {
+	return s.Normalize()
 }
 
 type Cartesian struct {
@@ -206,8 +202,12 @@ func (c *Cartesian) ToSpherical() *Spherical {
 
 type Coordinate interface {
 	ConvertTo(string) Coordinate
+	Convert(string) (Coordinate, error)
+	System() string
 	ToCartesian() *Cartesian
 	Offset(*Angle, *Angle) Coordinate
+	OffsetBy(*Angle, *Angle) Coordinate
+	SphericalOffsetsTo(Coordinate) (*Angle, *Angle, error)
 	GetX() *Angle
 	GetY() *Angle
 }
@@ -222,6 +222,32 @@ func (c coordinate) Offset(xoff, yoff *Angle) Coordinate {
 	return NewCoordinateFromSphere(c.system, s)
 }
 
+func (c coordinate) OffsetBy(sep, pa *Angle) Coordinate {
+	s := c.Spherical.OffsetBy(sep, pa)
+	return NewCoordinateFromSphere(c.system, s)
+}
+
+// SphericalOffsetsTo returns the offsets of other from c, see Spherical.SphericalOffsetsTo.
+// other is converted into the system of c.
+func (c coordinate) SphericalOffsetsTo(other Coordinate) (*Angle, *Angle, error) {
+	if other.System() != c.system {
+		var err error
+		if other, err = other.Convert(c.system); err != nil {
+			return nil, nil, err
+		}
+	}
+	lon, lat := c.Spherical.SphericalOffsetsTo(&Spherical{X: other.GetX(), Y: other.GetY()})
+	return lon, lat, nil
+}
+
+func (c coordinate) String() string {
+	return fmt.Sprintf(`%s, X: %s, Y: %s`, c.system, c.X.String(`deg`), c.Y.String(`deg`))
+}
+
+func (c coordinate) System() string {
+	return c.system
+}
+
 func (c coordinate) GetX() *Angle {
 	return c.X
 }
@@ -238,51 +264,81 @@ type J2000 struct {
 	*coordinate
 }
 
+type ICRS struct {
+	*coordinate
+}
+
+// FK5 of an arbitrary equinox (JD). FK5 J2000 is represented by J2000.
+type FK5 struct {
+	*coordinate
+	Equinox float64
+}
+
+// EquatorialOfDate is used for the mean and the true equator and equinox of date (JD).
+type EquatorialOfDate struct {
+	*coordinate
+	Equinox float64
+}
+
+// FK4 of an arbitrary equinox and epoch of observation (JD).
+// FK4 with both at B1950 is represented by B1950.
+type FK4 struct {
+	*coordinate
+	Equinox float64
+	Epoch   float64
+}
+
 type Gal struct {
 	*coordinate
 }
 
+type SuperGal struct {
+	*coordinate
+}
+
+// Ecliptic is used for both the mean ecliptic of J2000 and the mean ecliptic of date.
+type Ecliptic struct {
+	*coordinate
+}
+
+// ParseSystem returns the canonical name of a registered system.
+// Names and aliases are case-insensitive, e.g. "fk5" and "galactic" are accepted.
+func ParseSystem(system string) (string, error) {
+	f, err := LookupFrame(system)
+	if err != nil {
+		return ``, err
+	}
+	return f.Name, nil
+}
+
+/* ConvertTo is like Convert, but exits the program on error. */
 func (c coordinate) ConvertTo(newsystem string) Coordinate {
-	switch c.system {
-	case `J2000`:
-		switch newsystem {
-		case `J2000`:
-			return c
-		case `B1950`:
-			return J2000ToB1950(&c)
-		case `Gal`:
-			return J2000ToGal(&c)
-		default:
-			log.Fatal(fmt.Errorf("Unknown newsystem %s", newsystem))
-		}
-	case `B1950`:
-		switch newsystem {
-		case `J2000`:
-			return B1950ToJ2000(&c)
-		case `B1950`:
-			return c
-		case `Gal`:
-			return B1950ToGal(&c)
-		default:
-			log.Fatal(fmt.Errorf("Unknown newsystem %s", newsystem))
-		}
-	case `Gal`:
-		switch newsystem {
-		case `J2000`:
-			return GalToJ2000(&c)
-		case `B1950`:
-			return GalToB1950(&c)
-		case `Gal`:
-			return c
-		default:
-			log.Fatal(fmt.Errorf("Unknown newsystem %s", newsystem))
-		}
-	default:
-		log.Fatal(fmt.Errorf("Unknown input system %s", c.system))
+	converted, err := c.Convert(newsystem)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return converted
+}
+
+func (c coordinate) Convert(newsystem string) (Coordinate, error) {
+	from, err := lookupFrameNode(c.system)
+	if err != nil {
+		return nil, err
+	}
+	to, err := lookupFrameNode(newsystem)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if from.frame.Name == to.frame.Name {
+		return CoordinateOfSphere(to.frame.Name, c.Spherical)
+	}
+	s := convertVector(c.ToCartesian(), from, to).ToSpherical().Normalize()
+	return CoordinateOfSphere(to.frame.Name, s)
 }
 
+/* Generator */
+// The New* functions exit the program on an unknown system,
+// while the *Of functions return an error instead.
 func NewCoordinate(system string, x, y float64) Coordinate {
 	s := &Spherical{X: NewAngle(x), Y: NewAngle(y)}
 	return NewCoordinateFromSphere(system, s)
@@ -294,18 +350,33 @@ func NewCoordinateFromAngles(system string, x, y *Angle) Coordinate {
 }
 
 func NewCoordinateFromSphere(system string, s *Spherical) Coordinate {
-	c := coordinate{Spherical: s, system: system}
-	switch system {
-	case `J2000`:
-		return &J2000{&c}
-	case `B1950`:
-		return &B1950{&c}
-	case `Gal`:
-		return &Gal{&c}
-	default:
-		log.Fatal(fmt.Errorf("Unknwon system %s", system))
+	c, err := CoordinateOfSphere(system, s)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return nil
+	return c
+}
+
+func CoordinateOf(system string, x, y float64) (Coordinate, error) {
+	s := &Spherical{X: NewAngle(x), Y: NewAngle(y)}
+	return CoordinateOfSphere(system, s)
+}
+
+func CoordinateOfAngles(system string, x, y *Angle) (Coordinate, error) {
+	s := &Spherical{X: x, Y: y}
+	return CoordinateOfSphere(system, s)
+}
+
+func CoordinateOfSphere(system string, s *Spherical) (Coordinate, error) {
+	f, err := LookupFrame(system)
+	if err != nil {
+		return nil, err
+	}
+	c := &coordinate{Spherical: s, system: f.Name}
+	if f.Wrap != nil {
+		return f.Wrap(c), nil
+	}
+	return c, nil
 }
 
 func (coord *B1950) String() string {
@@ -332,6 +403,54 @@ func (coord *J2000) Dec() *Angle {
 	return coord.Y
 }
 
+func (coord *ICRS) String() string {
+	return fmt.Sprintf(`ICRS, RA: %s, DEC: %s`, coord.X.String(`hms`), coord.Y.String(`dms`))
+}
+
+func (coord *ICRS) Ra() *Angle {
+	return coord.X
+}
+
+func (coord *ICRS) Dec() *Angle {
+	return coord.Y
+}
+
+func (coord *FK5) String() string {
+	return fmt.Sprintf(`%s, RA: %s, DEC: %s`, coord.system, coord.X.String(`hms`), coord.Y.String(`dms`))
+}
+
+func (coord *FK5) Ra() *Angle {
+	return coord.X
+}
+
+func (coord *FK5) Dec() *Angle {
+	return coord.Y
+}
+
+func (coord *FK4) String() string {
+	return fmt.Sprintf(`%s, RA: %s, DEC: %s`, coord.system, coord.X.String(`hms`), coord.Y.String(`dms`))
+}
+
+func (coord *FK4) Ra() *Angle {
+	return coord.X
+}
+
+func (coord *FK4) Dec() *Angle {
+	return coord.Y
+}
+
+func (coord *EquatorialOfDate) String() string {
+	return fmt.Sprintf(`%s, RA: %s, DEC: %s`, coord.system, coord.X.String(`hms`), coord.Y.String(`dms`))
+}
+
+func (coord *EquatorialOfDate) Ra() *Angle {
+	return coord.X
+}
+
+func (coord *EquatorialOfDate) Dec() *Angle {
+	return coord.Y
+}
+
 func (coord *Gal) String() string {
 	return fmt.Sprintf(`Galac, Lat: %s, Lon: %s`, coord.X.String(`deg`), coord.Y.String(`deg`))
 }
@@ -343,3 +462,27 @@ func (coord *Gal) Longitude() *Angle {
 func (coord *Gal) Latitude() *Angle {
 	return coord.Y
 }
+
+func (coord *SuperGal) String() string {
+	return fmt.Sprintf(`SuperGal, Lon: %s, Lat: %s`, coord.X.String(`deg`), coord.Y.String(`deg`))
+}
+
+func (coord *SuperGal) Longitude() *Angle {
+	return coord.X
+}
+
+func (coord *SuperGal) Latitude() *Angle {
+	return coord.Y
+}
+
+func (coord *Ecliptic) String() string {
+	return fmt.Sprintf(`%s, Lon: %s, Lat: %s`, coord.system, coord.X.String(`deg`), coord.Y.String(`deg`))
+}
+
+func (coord *Ecliptic) Longitude() *Angle {
+	return coord.X
+}
+
+func (coord *Ecliptic) Latitude() *Angle {
+	return coord.Y
+}
@@ -0,0 +1,120 @@
+package coordinate
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	AU_METER          float64 = 149597870700.
+	SPEED_OF_LIGHT    float64 = 299792458.                         // m/s
+	LIGHT_TIME_AU_DAY float64 = AU_METER / SPEED_OF_LIGHT / 86400. // days per AU
+	SECONDS_PER_DAY   float64 = 86400.
+
+	/* NAIF integer codes used with Ephemeris */
+	NAIF_SOLAR_SYSTEM_BARYCENTER int = 0
+	NAIF_SUN                     int = 10
+	NAIF_MOON                    int = 301
+	NAIF_EARTH                   int = 399
+)
+
+var (
+	ErrNoEphemeris = errors.New(`no ephemeris is set`)
+)
+
+/* Numerical ephemeris */
+// Ephemeris gives the position [km] and the velocity [km/s] in ICRS of target relative to center
+// at jd (TDB), where the bodies are NAIF codes. It is implemented by spk.Kernel for JPL DE files.
+type Ephemeris interface {
+	PositionVelocity(target, center int, jd float64) ([3]float64, [3]float64, error)
+}
+
+var (
+	ephemerisMu sync.RWMutex
+	ephemeris   Ephemeris
+)
+
+// SetEphemeris makes the Earth in the apparent places and the planets use e where it covers
+// the time, and enables PRECISION_HIGH for the Sun and the Moon.
+// Setting nil restores the analytic theories only.
+func SetEphemeris(e Ephemeris) {
+	ephemerisMu.Lock()
+	defer ephemerisMu.Unlock()
+	ephemeris = e
+}
+
+func currentEphemeris() Ephemeris {
+	ephemerisMu.RLock()
+	defer ephemerisMu.RUnlock()
+	return ephemeris
+}
+
+// barycentricState returns the position [AU] and the velocity [AU/day] in ICRS of body
+// relative to the solar system barycenter at jd (TDB).
+func barycentricState(e Ephemeris, body int, jd float64) (*Cartesian, *Cartesian, error) {
+	p, v, err := e.PositionVelocity(body, NAIF_SOLAR_SYSTEM_BARYCENTER, jd)
+	if err != nil {
+		return nil, nil, err
+	}
+	pos := &Cartesian{X: p[0], Y: p[1], Z: p[2]}
+	vel := &Cartesian{X: v[0], Y: v[1], Z: v[2]}
+	return pos.Scale(1e3 / AU_METER), vel.Scale(1e3 * SECONDS_PER_DAY / AU_METER), nil
+}
+
+// earthPositionVelocity returns the heliocentric position [AU] and the barycentric velocity [AU/day]
+// in ICRS of the geocenter at jd (TDB).
+func earthPositionVelocity(jd float64) (*Cartesian, *Cartesian) {
+	if e := currentEphemeris(); e != nil {
+		earth, vel, err := barycentricState(e, NAIF_EARTH, jd)
+		if err == nil {
+			if sun, _, err := barycentricState(e, NAIF_SUN, jd); err == nil {
+				return earth.Sub(sun), vel
+			}
+		}
+	}
+	return earthICRS(jd), earthBarycentricVelocity(jd)
+}
+
+// Mass ratios of the planets (with their satellites) to the Sun (IAU 2009)
+var planetMassRatios = map[string]float64{
+	PLANET_MERCURY: 1. / 6023597.4,
+	PLANET_VENUS:   1. / 408523.719,
+	PLANET_MARS:    1. / 3098703.59,
+	PLANET_JUPITER: 1. / 1047.348644,
+	PLANET_SATURN:  1. / 3497.9018,
+	PLANET_URANUS:  1. / 22902.98,
+	PLANET_NEPTUNE: 1. / 19412.26,
+}
+
+const (
+	EARTH_MOON_MASS_RATIO float64 = 1. / 328900.5596
+)
+
+// sunBarycentric returns the position [AU] of the Sun in ICRS relative to the solar system
+// barycenter at jd (TDB), from the VSOP87 positions of the planets. The Earth stands for
+// the Earth-Moon barycenter, which is less than 5000 km away.
+func sunBarycentric(jd float64) *Cartesian {
+	sum := earthICRS(jd).Scale(EARTH_MOON_MASS_RATIO)
+	total := 1. + EARTH_MOON_MASS_RATIO
+	for _, name := range Planets() {
+		series, ratio := planetSeries[name], planetMassRatios[name]
+		sum = sum.Add(series.icrs(jd).Scale(ratio))
+		total += ratio
+	}
+	return sum.Scale(-1. / total)
+}
+
+// earthBarycentricVelocity returns the velocity [AU/day] in ICRS of the geocenter relative to
+// the solar system barycenter at jd (TDB), from the ephemeris if it covers jd, or from VSOP87
+// (accurate to about 1 m/s).
+func earthBarycentricVelocity(jd float64) *Cartesian {
+	if e := currentEphemeris(); e != nil {
+		if _, vel, err := barycentricState(e, NAIF_EARTH, jd); err == nil {
+			return vel
+		}
+	}
+	dt := 0.01
+	p1 := earthICRS(jd - dt).Add(sunBarycentric(jd - dt))
+	p2 := earthICRS(jd + dt).Add(sunBarycentric(jd + dt))
+	return p2.Sub(p1).Scale(0.5 / dt)
+}
@@ -0,0 +1,24 @@
+package coordinate
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownSystem = errors.New(`unknown coordinate system`)
+)
+
+// SystemError is returned when a system name cannot be resolved.
+// It wraps ErrUnknownSystem, so callers can test it with errors.Is.
+type SystemError struct {
+	System string
+}
+
+func (e *SystemError) Error() string {
+	return fmt.Sprintf("%s %q", ErrUnknownSystem, e.System)
+}
+
+func (e *SystemError) Unwrap() error {
+	return ErrUnknownSystem
+}
@@ -0,0 +1,208 @@
+package coordinate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// Hub of the frame graph. Every other frame is connected to it through its parents.
+	SYSTEM_ICRS string = `ICRS`
+)
+
+var (
+	ErrFrameExists = errors.New(`coordinate frame already registered`)
+)
+
+// Transform maps a direction (or position) vector from one frame into another.
+type Transform func(*Cartesian) *Cartesian
+
+// Frame describes a coordinate system registered with RegisterFrame.
+// ToParent and FromParent convert vectors between the frame and its Parent,
+// and conversions between any two frames are found by walking the parents.
+// Wrap optionally converts the generic Coordinate into a frame-specific type
+// (e.g. *J2000) when coordinates of the frame are created.
+type Frame struct {
+	Name       string
+	Aliases    []string
+	Parent     string
+	ToParent   Transform
+	FromParent Transform
+	Wrap       func(Coordinate) Coordinate
+}
+
+// NewRotationFrame returns a frame related to its parent by a fixed rotation m,
+// which maps vectors in the parent frame into the new frame.
+func NewRotationFrame(name, parent string, m Matrix, aliases ...string) *Frame {
+	mt := m.Transpose()
+	return &Frame{
+		Name:       name,
+		Aliases:    aliases,
+		Parent:     parent,
+		ToParent:   mt.Apply,
+		FromParent: m.Apply,
+	}
+}
+
+// FrameFamily builds a frame from its parameters, e.g. the equinox in "EclipticOfDate(J2020.5)".
+// The returned frame should have a canonical name, so that different spellings
+// of the same parameters resolve to a single frame.
+type FrameFamily func(args []string) (*Frame, error)
+
+type frameNode struct {
+	frame  *Frame
+	parent *frameNode
+	depth  int
+}
+
+var (
+	framesMu sync.RWMutex
+	frames   = map[string]*frameNode{
+		strings.ToLower(SYSTEM_ICRS): &frameNode{frame: &Frame{
+			Name: SYSTEM_ICRS,
+			Wrap: func(c Coordinate) Coordinate { return &ICRS{c.(*coordinate)} },
+		}},
+	}
+	families = map[string]FrameFamily{}
+)
+
+// RegisterFrame adds f to the frame graph. The parent must already be registered,
+// and neither the name nor the aliases may collide with existing frames.
+func RegisterFrame(f *Frame) error {
+	framesMu.Lock()
+	defer framesMu.Unlock()
+
+	parent, ok := frames[strings.ToLower(f.Parent)]
+	if !ok {
+		return &SystemError{System: f.Parent}
+	}
+	if f.ToParent == nil || f.FromParent == nil {
+		return fmt.Errorf("frame %s: transforms to and from %s are required", f.Name, f.Parent)
+	}
+	keys := []string{strings.ToLower(f.Name)}
+	for _, alias := range f.Aliases {
+		keys = append(keys, strings.ToLower(alias))
+	}
+	for _, key := range keys {
+		if _, ok := frames[key]; ok {
+			return fmt.Errorf("%w: %s", ErrFrameExists, key)
+		}
+	}
+	node := &frameNode{frame: f, parent: parent, depth: parent.depth + 1}
+	for _, key := range keys {
+		frames[key] = node
+	}
+	return nil
+}
+
+// RegisterFrameFamily makes frames named "name(arg1, arg2, ...)" available.
+// They are created by family whenever they are looked up.
+func RegisterFrameFamily(name string, family FrameFamily) error {
+	framesMu.Lock()
+	defer framesMu.Unlock()
+	key := strings.ToLower(name)
+	if _, ok := families[key]; ok {
+		return fmt.Errorf("%w: %s", ErrFrameExists, key)
+	}
+	families[key] = family
+	return nil
+}
+
+func mustRegisterFrame(f *Frame) {
+	if err := RegisterFrame(f); err != nil {
+		panic(err)
+	}
+}
+
+// LookupFrame returns the registered frame whose name or alias is system (case-insensitive).
+func LookupFrame(system string) (*Frame, error) {
+	node, err := lookupFrameNode(system)
+	if err != nil {
+		return nil, err
+	}
+	return node.frame, nil
+}
+
+func lookupFrameNode(system string) (*frameNode, error) {
+	key := strings.ToLower(system)
+	framesMu.RLock()
+	node, ok := frames[key]
+	framesMu.RUnlock()
+	if ok {
+		return node, nil
+	}
+	return lookupFamilyNode(system)
+}
+
+func lookupFamilyNode(system string) (*frameNode, error) {
+	open := strings.Index(system, `(`)
+	if open < 0 || !strings.HasSuffix(system, `)`) {
+		return nil, &SystemError{System: system}
+	}
+	framesMu.RLock()
+	family, ok := families[strings.ToLower(strings.TrimSpace(system[:open]))]
+	framesMu.RUnlock()
+	if !ok {
+		return nil, &SystemError{System: system}
+	}
+
+	f, err := family(splitArgs(system[open+1 : len(system)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", system, err)
+	}
+
+	// Family frames are not cached, since frames of date would pile up for every instant.
+	framesMu.RLock()
+	node, ok := frames[strings.ToLower(f.Name)]
+	framesMu.RUnlock()
+	if ok {
+		return node, nil
+	}
+	// The parent may itself be a family frame, e.g. an offset frame in FK5(J2010).
+	parent, err := lookupFrameNode(f.Parent)
+	if err != nil {
+		return nil, err
+	}
+	return &frameNode{frame: f, parent: parent, depth: parent.depth + 1}, nil
+}
+
+// splitArgs splits the arguments of a family frame at the commas outside parentheses.
+func splitArgs(s string) []string {
+	args := make([]string, 0, 2)
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(s[start:]))
+}
+
+// convertVector transforms v from one frame into another, going up from the
+// source frame to the closest common ancestor and then down to the target frame.
+func convertVector(v *Cartesian, from, to *frameNode) *Cartesian {
+	down := make([]*frameNode, 0, to.depth)
+	for from != to {
+		if from.depth >= to.depth {
+			v = from.frame.ToParent(v)
+			from = from.parent
+		} else {
+			down = append(down, to)
+			to = to.parent
+		}
+	}
+	for i := len(down) - 1; i >= 0; i-- {
+		v = down[i].frame.FromParent(v)
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+package coordinate
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+/* Horizontal coordinates */
+// AltAz is the position of a source seen from an observatory at an instant.
+// The azimuth is measured from north through east, and the elevation
+// from the horizon. Aberration and light deflection are included,
+// but polar motion is not. El is the geometric elevation;
+// see ObservedElevation for the refracted one.
+type AltAz struct {
+	Az          *Angle
+	El          *Angle
+	Observatory Observatory
+	Time        astrotime.Instant
+}
+
+func NewAltAz(az, el *Angle, o Observatory, t astrotime.Instant) *AltAz {
+	return &AltAz{Az: az, El: el, Observatory: o, Time: t}
+}
+
+// NewObservedAltAz returns the horizontal coordinate of the observed (refracted)
+// elevation el, using the refraction model m, or that of o if m is nil.
+func NewObservedAltAz(az, el *Angle, o Observatory, t astrotime.Instant, m RefractionModel) *AltAz {
+	return NewAltAz(az, Unrefract(refractionAt(o, m), el), o, t)
+}
+
+// ToAltAz returns the horizontal coordinate of c seen from o at t.
+func ToAltAz(c Coordinate, o Observatory, t astrotime.Instant) (*AltAz, error) {
+	icrs, err := c.Convert(SYSTEM_ICRS)
+	if err != nil {
+		return nil, err
+	}
+	ha, dec := hourAngleDec(astrometricToApparent(icrs.ToCartesian(), o, t), o, t)
+	az, el := equatorialToHorizontal(ha, dec, o.Latitude().Radian())
+	return NewAltAz(NewAngle(RadToDeg(az)), NewAngle(RadToDeg(el)), o, t), nil
+}
+
+// hourAngleDec returns the hour angle and the declination (true equator of date)
+// of the apparent direction v (in ICRS axes) in radian.
+func hourAngleDec(v *Cartesian, o Observatory, t astrotime.Instant) (float64, float64) {
+	s := trueOfDateMatrix(t.JD(astrotime.TT)).Apply(v).ToSpherical()
+	return LST(o, t).Radian() - s.X.Radian(), s.Y.Radian()
+}
+
+func equatorialToHorizontal(ha, dec, lat float64) (float64, float64) {
+	sinHa, cosHa := math.Sincos(ha)
+	sinDec, cosDec := math.Sincos(dec)
+	sinLat, cosLat := math.Sincos(lat)
+	az := math.Atan2(-cosDec*sinHa, sinDec*cosLat-cosDec*cosHa*sinLat)
+	el := math.Asin(sinLat*sinDec + cosLat*cosDec*cosHa)
+	return DegToRad(normalizeDegree(RadToDeg(az))), el
+}
+
+func horizontalToEquatorial(az, el, lat float64) (float64, float64) {
+	sinAz, cosAz := math.Sincos(az)
+	sinEl, cosEl := math.Sincos(el)
+	sinLat, cosLat := math.Sincos(lat)
+	ha := math.Atan2(-sinAz*cosEl, sinEl*cosLat-cosEl*cosAz*sinLat)
+	dec := math.Asin(sinLat*sinEl + cosLat*cosEl*cosAz)
+	return ha, dec
+}
+
+func (h *AltAz) String() string {
+	return fmt.Sprintf(`AltAz (%s, %s), Az: %s, El: %s`, h.Observatory.Name(), h.Time, h.Az.String(`deg`), h.El.String(`deg`))
+}
+
+func (h *AltAz) Azimuth() *Angle {
+	return h.Az
+}
+
+func (h *AltAz) Elevation() *Angle {
+	return h.El
+}
+
+// ObservedElevation returns the elevation including the refraction model m,
+// or that of the observatory if m is nil.
+func (h *AltAz) ObservedElevation(m RefractionModel) *Angle {
+	return Refract(refractionAt(h.Observatory, m), h.El)
+}
+
+// HourAngle returns the hour angle in (-180, 180] deg, positive to the west.
+func (h *AltAz) HourAngle() *Angle {
+	ha, _ := horizontalToEquatorial(h.Az.Radian(), h.El.Radian(), h.Observatory.Latitude().Radian())
+	return NewAngle(RadToDeg(ha))
+}
+
+// ParallacticAngle returns the angle between the directions to the zenith
+// and to the north celestial pole at the source, positive to the west of the meridian.
+func (h *AltAz) ParallacticAngle() *Angle {
+	lat := h.Observatory.Latitude().Radian()
+	ha, dec := horizontalToEquatorial(h.Az.Radian(), h.El.Radian(), lat)
+	q := math.Atan2(math.Sin(ha), math.Tan(lat)*math.Cos(dec)-math.Sin(dec)*math.Cos(ha))
+	return NewAngle(RadToDeg(q))
+}
+
+// ToJ2000 returns the equatorial coordinate of the horizontal direction.
+func (h *AltAz) ToJ2000() Coordinate {
+	ha, dec := horizontalToEquatorial(h.Az.Radian(), h.El.Radian(), h.Observatory.Latitude().Radian())
+	ra := LST(h.Observatory, h.Time).Radian() - ha
+	v := (&Spherical{X: NewAngle(RadToDeg(ra)), Y: NewAngle(RadToDeg(dec))}).ToCartesian()
+	v = trueOfDateMatrix(h.Time.JD(astrotime.TT)).Transpose().Apply(v)
+	v = apparentToAstrometric(v, h.Observatory, h.Time)
+	icrs := NewCoordinateFromSphere(SYSTEM_ICRS, v.ToSpherical().Normalize())
+	return icrs.ConvertTo(SYSTEM_J2000)
+}
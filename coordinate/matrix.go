@@ -0,0 +1,78 @@
+package coordinate
+
+import (
+	"math"
+)
+
+/* Rotation matrix */
+// The rotations follow the usual astrometric convention: RotationZ(a) rotates
+// the coordinate axes (not the vector) by a radian around the z axis.
+type Matrix [3][3]float64
+
+func IdentityMatrix() Matrix {
+	return Matrix{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+}
+
+func RotationX(rad float64) Matrix {
+	s, c := math.Sincos(rad)
+	return Matrix{{1, 0, 0}, {0, c, s}, {0, -s, c}}
+}
+
+func RotationY(rad float64) Matrix {
+	s, c := math.Sincos(rad)
+	return Matrix{{c, 0, -s}, {0, 1, 0}, {s, 0, c}}
+}
+
+func RotationZ(rad float64) Matrix {
+	s, c := math.Sincos(rad)
+	return Matrix{{c, s, 0}, {-s, c, 0}, {0, 0, 1}}
+}
+
+func (m Matrix) Apply(v *Cartesian) *Cartesian {
+	return &Cartesian{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+func (m Matrix) Transpose() Matrix {
+	var t Matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			t[i][j] = m[j][i]
+		}
+	}
+	return t
+}
+
+// Multiply returns m*n, i.e. the rotation n followed by m.
+func (m Matrix) Multiply(n Matrix) Matrix {
+	var p Matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				p[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+	return p
+}
+
+func (m Matrix) Inverse() Matrix {
+	var inv Matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			i1, i2 := (j+1)%3, (j+2)%3
+			j1, j2 := (i+1)%3, (i+2)%3
+			inv[i][j] = m[i1][j1]*m[i2][j2] - m[i1][j2]*m[i2][j1]
+		}
+	}
+	det := m[0][0]*inv[0][0] + m[0][1]*inv[1][0] + m[0][2]*inv[2][0]
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			inv[i][j] /= det
+		}
+	}
+	return inv
+}
@@ -0,0 +1,214 @@
+package coordinate
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	// Precession-nutation models: IAU 2006 precession with either the IAU 2000B nutation
+	// (within 1 mas of IAU 2000A, without the celestial pole offsets published by IERS)
+	// or its 13 largest terms (about 50 mas).
+	PRECESSION_NUTATION_FULL string = `full`
+	PRECESSION_NUTATION_FAST string = `fast`
+
+	NUTATION_FAST_TERMS int = 13
+
+	// Number of precession-nutation matrices kept for frames of date
+	PRECESSION_NUTATION_CACHE_SIZE int = 256
+)
+
+/* Nutation */
+// Luni-solar nutation series of IAU 2000B (McCarthy & Luzum 2003).
+// Each row holds the multipliers of l, l', F, D and Omega, followed by the coefficients
+// of longitude (S, S', C) and obliquity (C, C', S) in 0.1 microarcsec.
+var nutationTerms = [][11]float64{
+	{0, 0, 0, 0, 1, -172064161, -174666, 33386, 92052331, 9086, 15377},
+	{0, 0, 2, -2, 2, -13170906, -1675, -13696, 5730336, -3015, -4587},
+	{0, 0, 2, 0, 2, -2276413, -234, 2796, 978459, -485, 1374},
+	{0, 0, 0, 0, 2, 2074554, 207, -698, -897492, 470, -291},
+	{0, 1, 0, 0, 0, 1475877, -3633, 11817, 73871, -184, -1924},
+	{0, 1, 2, -2, 2, -516821, 1226, -524, 224386, -677, -174},
+	{1, 0, 0, 0, 0, 711159, 73, -872, -6750, 0, 358},
+	{0, 0, 2, 0, 1, -387298, -367, 380, 200728, 18, 318},
+	{1, 0, 2, 0, 2, -301461, -36, 816, 129025, -63, 367},
+	{0, -1, 2, -2, 2, 215829, -494, 111, -95929, 299, 132},
+	{0, 0, 2, -2, 1, 128227, 137, 181, -68982, -9, 39},
+	{-1, 0, 2, 0, 2, 123457, 11, 19, -53311, 32, -4},
+	{-1, 0, 0, 2, 0, 156994, 10, -168, -1235, 0, 82},
+	{1, 0, 0, 0, 1, 63110, 63, 27, -33228, 0, -9},
+	{-1, 0, 0, 0, 1, -57976, -63, -189, 31429, 0, -75},
+	{-1, 0, 2, 2, 2, -59641, -11, 149, 25543, -11, 66},
+	{1, 0, 2, 0, 1, -51613, -42, 129, 26366, 0, 78},
+	{-2, 0, 2, 0, 1, 45893, 50, 31, -24236, -10, 20},
+	{0, 0, 0, 2, 0, 63384, 11, -150, -1220, 0, 29},
+	{0, 0, 2, 2, 2, -38571, -1, 158, 16452, -11, 68},
+	{0, -2, 2, -2, 2, 32481, 0, 0, -13870, 0, 0},
+	{-2, 0, 0, 2, 0, -47722, 0, -18, 477, 0, -25},
+	{2, 0, 2, 0, 2, -31046, -1, 131, 13238, -11, 59},
+	{1, 0, 2, -2, 2, 28593, 0, -1, -12338, 10, -3},
+	{-1, 0, 2, 0, 1, 20441, 21, 10, -10758, 0, -3},
+	{2, 0, 0, 0, 0, 29243, 0, -74, -609, 0, 13},
+	{0, 0, 2, 0, 0, 25887, 0, -66, -550, 0, 11},
+	{0, 1, 0, 0, 1, -14053, -25, 79, 8551, -2, -45},
+	{-1, 0, 0, 2, 1, 15164, 10, 11, -8001, 0, -1},
+	{0, 2, 2, -2, 2, -15794, 72, -16, 6850, -42, -5},
+	{0, 0, -2, 2, 0, 21783, 0, 13, -167, 0, 13},
+	{1, 0, 0, -2, 1, -12873, -10, -37, 6953, 0, -14},
+	{0, -1, 0, 0, 1, -12654, 11, 63, 6415, 0, 26},
+	{-1, 0, 2, 2, 1, -10204, 0, 25, 5222, 0, 15},
+	{0, 2, 0, 0, 0, 16707, -85, -10, 168, -1, 10},
+	{1, 0, 2, 2, 2, -7691, 0, 44, 3268, 0, 19},
+	{-2, 0, 2, 0, 0, -11024, 0, -14, 104, 0, 2},
+	{0, 1, 2, 0, 2, 7566, -21, -11, -3250, 0, -5},
+	{0, 0, 2, 2, 1, -6637, -11, 25, 3353, 0, 14},
+	{0, -1, 2, 0, 2, -7141, 21, 8, 3070, 0, 4},
+	{0, 0, 0, 2, 1, -6302, -11, 2, 3272, 0, 4},
+	{1, 0, 2, -2, 1, 5800, 10, 2, -3045, 0, -1},
+	{2, 0, 2, -2, 2, 6443, 0, -7, -2768, 0, -4},
+	{-2, 0, 0, 2, 1, -5774, -11, -15, 3041, 0, -5},
+	{2, 0, 2, 0, 1, -5350, 0, 21, 2695, 0, 12},
+	{0, -1, 2, -2, 1, -4752, -11, -3, 2719, 0, -3},
+	{0, 0, 0, -2, 1, -4940, -11, -21, 2720, 0, -9},
+	{-1, -1, 0, 2, 0, 7350, 0, -8, -51, 0, 4},
+	{2, 0, 0, -2, 1, 4065, 0, 6, -2206, 0, 1},
+	{1, 0, 0, 2, 0, 6579, 0, -24, -199, 0, 2},
+	{0, 1, 2, -2, 1, 3579, 0, 5, -1900, 0, 1},
+	{1, -1, 0, 0, 0, 4725, 0, -6, -41, 0, 3},
+	{-2, 0, 2, 0, 2, -3075, 0, -2, 1313, 0, -1},
+	{3, 0, 2, 0, 2, -2904, 0, 15, 1233, 0, 7},
+	{0, -1, 0, 2, 0, 4348, 0, -10, -81, 0, 2},
+	{1, -1, 2, 0, 2, -2878, 0, 8, 1232, 0, 4},
+	{0, 0, 0, 1, 0, -4230, 0, 5, -20, 0, -2},
+	{-1, -1, 2, 2, 2, -2819, 0, 7, 1207, 0, 3},
+	{-1, 0, 2, 0, 0, -4056, 0, 5, 40, 0, -2},
+	{0, -1, 2, 2, 2, -2647, 0, 11, 1129, 0, 5},
+	{-2, 0, 0, 0, 1, -2294, 0, -10, 1266, 0, -4},
+	{1, 1, 2, 0, 2, 2481, 0, -7, -1062, 0, -3},
+	{2, 0, 0, 0, 1, 2179, 0, -2, -1129, 0, -2},
+	{-1, 1, 0, 1, 0, 3276, 0, 1, -9, 0, 0},
+	{1, 1, 0, 0, 0, -3389, 0, 5, 35, 0, -2},
+	{1, 0, 2, 0, 0, 3339, 0, -13, -107, 0, 1},
+	{-1, 0, 2, -2, 1, -1987, 0, -6, 1073, 0, -2},
+	{1, 0, 0, 0, 2, -1981, 0, 0, 854, 0, 0},
+	{-1, 0, 0, 1, 0, 4026, 0, -353, -553, 0, -139},
+	{0, 0, 2, 1, 2, 1660, 0, -5, -710, 0, -2},
+	{-1, 0, 2, 4, 2, -1521, 0, 9, 647, 0, 4},
+	{-1, 1, 0, 1, 1, 1314, 0, 0, -700, 0, 0},
+	{0, -2, 2, -2, 1, -1283, 0, 0, 672, 0, 0},
+	{1, 0, 2, 2, 1, -1331, 0, 8, 663, 0, 4},
+	{-2, 0, 2, 2, 2, 1383, 0, -2, -594, 0, -2},
+	{-1, 0, 0, 0, 2, 1405, 0, 4, -610, 0, 2},
+	{1, 1, 2, -2, 2, 1290, 0, 0, -556, 0, 0},
+}
+
+// Fixed offsets [mas] standing in for the planetary terms of IAU 2000A
+const (
+	NUTATION_PLANETARY_PSI float64 = -0.135
+	NUTATION_PLANETARY_EPS float64 = 0.388
+)
+
+// fundamentalArguments returns the Delaunay arguments l, l', F, D and Omega in radian
+// at jd (TT), linear in time as used by IAU 2000B.
+func fundamentalArguments(jd float64) [5]float64 {
+	t := julianCenturies(jd)
+	return [5]float64{
+		ArcsecToRad(math.Mod(485868.249036+1717915923.2178*t, 1296000.)),
+		ArcsecToRad(math.Mod(1287104.79305+129596581.0481*t, 1296000.)),
+		ArcsecToRad(math.Mod(335779.526232+1739527262.8478*t, 1296000.)),
+		ArcsecToRad(math.Mod(1072260.70369+1602961601.2090*t, 1296000.)),
+		ArcsecToRad(math.Mod(450160.398036-6962890.5431*t, 1296000.)),
+	}
+}
+
+// Nutation returns the nutation in longitude and in obliquity at jd (TT) in radian.
+func Nutation(jd float64) (float64, float64) {
+	return nutation(jd, nutationTerms)
+}
+
+// NutationFast is the same as Nutation, but uses only the largest terms of the series.
+func NutationFast(jd float64) (float64, float64) {
+	return nutation(jd, nutationTerms[:NUTATION_FAST_TERMS])
+}
+
+func nutation(jd float64, terms [][11]float64) (float64, float64) {
+	t := julianCenturies(jd)
+	args := fundamentalArguments(jd)
+	var dpsi, deps float64
+	// Sum from the smallest terms to keep the rounding errors small.
+	for i := len(terms) - 1; i >= 0; i-- {
+		term := terms[i]
+		arg := 0.
+		for j := 0; j < 5; j++ {
+			arg += term[j] * args[j]
+		}
+		sin, cos := math.Sincos(math.Mod(arg, 2.*math.Pi))
+		dpsi += (term[5]+term[6]*t)*sin + term[7]*cos
+		deps += (term[8]+term[9]*t)*cos + term[10]*sin
+	}
+	dpsi = ArcsecToRad(dpsi*1e-7 + NUTATION_PLANETARY_PSI*1e-3)
+	deps = ArcsecToRad(deps*1e-7 + NUTATION_PLANETARY_EPS*1e-3)
+	// Adjustments for the IAU 2006 precession (Capitaine et al. 2005)
+	fj2 := -2.7774e-6 * t
+	return dpsi * (1. + 0.4697e-6 + fj2), deps * (1. + fj2)
+}
+
+// NutationMatrix returns the rotation from the mean equator and equinox of jd (TT)
+// to the true equator and equinox of jd.
+func NutationMatrix(jd float64, model string) (Matrix, error) {
+	var dpsi, deps float64
+	switch model {
+	case PRECESSION_NUTATION_FULL:
+		dpsi, deps = Nutation(jd)
+	case PRECESSION_NUTATION_FAST:
+		dpsi, deps = NutationFast(jd)
+	default:
+		return Matrix{}, fmt.Errorf("unknown precession-nutation model %q", model)
+	}
+	eps := MeanObliquity(jd)
+	return RotationX(-(eps + deps)).Multiply(RotationZ(-dpsi)).Multiply(RotationX(eps)), nil
+}
+
+type precessionNutationKey struct {
+	jd    float64
+	model string
+}
+
+var (
+	precessionNutationMu    sync.Mutex
+	precessionNutationCache = map[precessionNutationKey]Matrix{}
+)
+
+// PrecessionNutationMatrix returns the rotation from the mean equator and equinox of J2000
+// to the true equator and equinox of jd (TT). Apply BIAS_MATRIX first for ICRS vectors.
+// The matrices of recent dates are cached, since frames of date are built on every lookup.
+func PrecessionNutationMatrix(jd float64, model string) (Matrix, error) {
+	key := precessionNutationKey{jd: jd, model: model}
+	precessionNutationMu.Lock()
+	m, ok := precessionNutationCache[key]
+	precessionNutationMu.Unlock()
+	if ok {
+		return m, nil
+	}
+
+	n, err := NutationMatrix(jd, model)
+	if err != nil {
+		return Matrix{}, err
+	}
+	m = n.Multiply(PrecessionMatrix(jd))
+
+	precessionNutationMu.Lock()
+	if len(precessionNutationCache) >= PRECESSION_NUTATION_CACHE_SIZE {
+		precessionNutationCache = map[precessionNutationKey]Matrix{}
+	}
+	precessionNutationCache[key] = m
+	precessionNutationMu.Unlock()
+	return m, nil
+}
+
+// trueOfDateMatrix returns the rotation from ICRS to the true equator and equinox of jd (TT).
+func trueOfDateMatrix(jd float64) Matrix {
+	pn, _ := PrecessionNutationMatrix(jd, PRECESSION_NUTATION_FULL)
+	return pn.Multiply(BIAS_MATRIX)
+}
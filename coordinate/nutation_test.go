@@ -0,0 +1,17 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+// SOFA iauNut00b test case (MJD 53736 TT)
+func TestNutation(t *testing.T) {
+	dpsi, deps := Nutation(2400000.5 + 53736.)
+	if math.Abs(dpsi-(-0.9632552291148362783e-5)) > 1e-11 {
+		t.Errorf("dpsi = %.15e", dpsi)
+	}
+	if math.Abs(deps-0.4063197106621159367e-4) > 1e-11 {
+		t.Errorf("deps = %.15e", deps)
+	}
+}
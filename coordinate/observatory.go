@@ -1,29 +1,87 @@
 package coordinate
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
 const (
-	/* ToDo: set collect values for following observatory*/
-	ASTE_LATITUDE        float64 = 0.1
-	ASTE_LONGITUDE       float64 = 0.1
-	EFFELSBERG_LATITUDE  float64 = 0.1
-	EFFELSBERG_LONGITUDE float64 = 0.1
-	GBT_LATITUDE         float64 = 0.1
-	GBT_LONGITUDE        float64 = 0.1
-	NRO_LATITUDE         float64 = 0.1
-	NRO_LONGITUDE        float64 = 0.1
-	VLA_LATITUDE         float64 = 0.1
-	VLA_LONGITUDE        float64 = 0.1
+	/* Geodetic (WGS84) latitude [deg], east longitude [deg] and ellipsoidal height [m] */
+	ALMA_LATITUDE        float64 = -23.0262015
+	ALMA_LONGITUDE       float64 = -67.7551257
+	ALMA_HEIGHT          float64 = 5064.
+	APEX_LATITUDE        float64 = -23.0057778
+	APEX_LONGITUDE       float64 = -67.7591667
+	APEX_HEIGHT          float64 = 5105.
+	ASTE_LATITUDE        float64 = -22.9715833
+	ASTE_LONGITUDE       float64 = -67.7032778
+	ASTE_HEIGHT          float64 = 4860.
+	EFFELSBERG_LATITUDE  float64 = 50.5248306
+	EFFELSBERG_LONGITUDE float64 = 6.8828250
+	EFFELSBERG_HEIGHT    float64 = 416.7
+	GBT_LATITUDE         float64 = 38.4331294
+	GBT_LONGITUDE        float64 = -79.8398397
+	GBT_HEIGHT           float64 = 824.36
+	IRAM30M_LATITUDE     float64 = 37.0684139
+	IRAM30M_LONGITUDE    float64 = -3.3987528
+	IRAM30M_HEIGHT       float64 = 2850.
+	JCMT_LATITUDE        float64 = 19.8228083
+	JCMT_LONGITUDE       float64 = -155.4770361
+	JCMT_HEIGHT          float64 = 4092.
+	NRO_LATITUDE         float64 = 35.9446944
+	NRO_LONGITUDE        float64 = 138.4725556
+	NRO_HEIGHT           float64 = 1350.
+	PARKES_LATITUDE      float64 = -32.9998361
+	PARKES_LONGITUDE     float64 = 148.2635167
+	PARKES_HEIGHT        float64 = 414.8
+	SMA_LATITUDE         float64 = 19.8242
+	SMA_LONGITUDE        float64 = -155.4781
+	SMA_HEIGHT           float64 = 4080.
+	VLA_LATITUDE         float64 = 34.0787492
+	VLA_LONGITUDE        float64 = -107.6177275
+	VLA_HEIGHT           float64 = 2124.
+
+	/* WGS84 ellipsoid */
+	WGS84_A float64 = 6378137.
+	WGS84_F float64 = 1. / 298.257223563
 )
 
+var (
+	ErrUnknownObservatory = errors.New(`unknown observatory`)
+	ErrObservatoryExists  = errors.New(`observatory already registered`)
+)
+
+// Observatory is a site on the Earth. The longitude is positive to the east,
+// and the latitude and the height are geodetic (WGS84).
 type Observatory interface {
 	Latitude() *Angle
 	Longitude() *Angle
+	Height() float64
+	Timezone() string
+	ITRS() *Cartesian
+	Horizon(*Angle) *Angle
+	Refraction() RefractionModel
 	Name() string
 }
 
+// HorizonPoint is a vertex of a horizon mask in degree.
+type HorizonPoint struct {
+	Azimuth   float64
+	Elevation float64
+}
+
 type observatory struct {
-	latitude  *Angle
-	longitude *Angle
-	name      string
+	latitude   *Angle
+	longitude  *Angle
+	height     float64
+	timezone   string
+	horizon    []HorizonPoint
+	refraction RefractionModel
+	name       string
 }
 
 /* Implement interface Observatory for observatory */
@@ -35,36 +93,216 @@ func (o *observatory) Longitude() *Angle {
 	return o.longitude
 }
 
+// Height returns the height above the WGS84 ellipsoid in meter.
+func (o *observatory) Height() float64 {
+	return o.height
+}
+
+// Timezone returns the IANA time zone name, e.g. "Asia/Tokyo".
+func (o *observatory) Timezone() string {
+	return o.timezone
+}
+
+// ITRS returns the geocentric position (X, Y, Z) of the observatory in meter.
+func (o *observatory) ITRS() *Cartesian {
+	lat := o.latitude.Radian()
+	lon := o.longitude.Radian()
+	e2 := WGS84_F * (2. - WGS84_F)
+	n := WGS84_A / math.Sqrt(1.-e2*math.Sin(lat)*math.Sin(lat))
+	return &Cartesian{
+		X: (n + o.height) * math.Cos(lat) * math.Cos(lon),
+		Y: (n + o.height) * math.Cos(lat) * math.Sin(lon),
+		Z: (n*(1.-e2) + o.height) * math.Sin(lat),
+	}
+}
+
+// Horizon returns the lowest visible elevation toward the azimuth az,
+// interpolated linearly between the points of the horizon mask.
+// Without a mask, the horizon is at 0 deg. RiseTransitSet measures its limit from it.
+func (o *observatory) Horizon(az *Angle) *Angle {
+	n := len(o.horizon)
+	if n == 0 {
+		return NewAngle(0.)
+	}
+	a := normalizeDegree(az.Degree())
+	i := sort.Search(n, func(i int) bool { return o.horizon[i].Azimuth > a })
+	prev := o.horizon[(i+n-1)%n]
+	next := o.horizon[i%n]
+	width := normalizeDegree(next.Azimuth - prev.Azimuth)
+	if width == 0 {
+		return NewAngle(prev.Elevation)
+	}
+	f := normalizeDegree(a-prev.Azimuth) / width
+	return NewAngle(prev.Elevation + f*(next.Elevation-prev.Elevation))
+}
+
+// Refraction returns the refraction model of the site, Bennett under the standard conditions
+// unless set with WithRefraction.
+func (o *observatory) Refraction() RefractionModel {
+	if o.refraction == nil {
+		return NewBennett()
+	}
+	return o.refraction
+}
+
 func (o *observatory) Name() string {
 	return o.name
 }
 
 /* IO */
 func NewObservatoryFromAngles(lat, lon *Angle, name string) Observatory {
-	return &observatory{latitude: lat, longitude: lon, name: name}
+	return &observatory{latitude: lat, longitude: lon, timezone: `UTC`, name: name}
 }
 
 func NewObservatory(lat, lon float64, name string) Observatory {
-	return &observatory{latitude: NewAngle(lat), longitude: NewAngle(lon), name: name}
+	return NewGeodeticObservatory(lat, lon, 0., `UTC`, name)
+}
+
+func NewGeodeticObservatory(lat, lon, height float64, timezone, name string) Observatory {
+	return NewObservatoryWithHorizon(lat, lon, height, timezone, name, nil)
+}
+
+func NewObservatoryWithHorizon(lat, lon, height float64, timezone, name string, mask []HorizonPoint) Observatory {
+	horizon := make([]HorizonPoint, len(mask))
+	for i, p := range mask {
+		horizon[i] = HorizonPoint{Azimuth: normalizeDegree(p.Azimuth), Elevation: p.Elevation}
+	}
+	sort.Slice(horizon, func(i, j int) bool { return horizon[i].Azimuth < horizon[j].Azimuth })
+	return &observatory{latitude: NewAngle(lat), longitude: NewAngle(lon), height: height, timezone: timezone, horizon: horizon, name: name}
+}
+
+// WithRefraction returns a copy of o with the refraction model m, or the default one if m is nil.
+func WithRefraction(o Observatory, m RefractionModel) Observatory {
+	if obs, ok := o.(*observatory); ok {
+		c := *obs
+		c.refraction = m
+		return &c
+	}
+	return &refractedObservatory{Observatory: o, refraction: m}
+}
+
+// refractedObservatory overrides the refraction model of another implementation of Observatory.
+type refractedObservatory struct {
+	Observatory
+	refraction RefractionModel
+}
+
+func (o *refractedObservatory) Refraction() RefractionModel {
+	if o.refraction == nil {
+		return o.Observatory.Refraction()
+	}
+	return o.refraction
+}
+
+/* Registry */
+var (
+	observatoriesMu sync.RWMutex
+	observatories   = map[string]func() Observatory{
+		`alma`:                ALMA,
+		`apex`:                APEX,
+		`aste`:                ASTE,
+		`effelsberg`:          Effelsberg,
+		`gbt`:                 GBT,
+		`greenbank`:           GBT,
+		`iram30m`:             IRAM30m,
+		`pico veleta`:         IRAM30m,
+		`jcmt`:                JCMT,
+		`nro`:                 NRO,
+		`nro45m`:              NRO,
+		`nobeyama`:            NRO,
+		`parkes`:              Parkes,
+		`sma`:                 SMA,
+		`submillimeter array`: SMA,
+		`vla`:                 VLA,
+	}
+)
+
+// LookupObservatory returns the observatory registered as name (case-insensitive),
+// either one of the built-in observatories or one added with RegisterObservatory.
+func LookupObservatory(name string) (Observatory, error) {
+	observatoriesMu.RLock()
+	newObservatory, ok := observatories[strings.ToLower(name)]
+	observatoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownObservatory, name)
+	}
+	return newObservatory(), nil
+}
+
+// RegisterObservatory makes o available to LookupObservatory by its name and aliases.
+func RegisterObservatory(o Observatory, aliases ...string) error {
+	return registerObservatories([]Observatory{o}, [][]string{aliases})
+}
+
+// registerObservatories registers obs[i] with aliases[i], either all of them or,
+// if any name collides with a registered one or with another in obs, none of them.
+func registerObservatories(obs []Observatory, aliases [][]string) error {
+	observatoriesMu.Lock()
+	defer observatoriesMu.Unlock()
+	added := map[string]Observatory{}
+	for i, o := range obs {
+		keys := []string{strings.ToLower(o.Name())}
+		for _, alias := range aliases[i] {
+			keys = append(keys, strings.ToLower(alias))
+		}
+		for _, key := range keys {
+			if _, ok := observatories[key]; ok {
+				return fmt.Errorf("%w: %s", ErrObservatoryExists, key)
+			}
+			if other, ok := added[key]; ok && other != o {
+				return fmt.Errorf("%w: %s", ErrObservatoryExists, key)
+			}
+			added[key] = o
+		}
+	}
+	for key, o := range added {
+		o := o
+		observatories[key] = func() Observatory { return o }
+	}
+	return nil
 }
 
 /* Actual observatories */
+func ALMA() Observatory {
+	return NewGeodeticObservatory(ALMA_LATITUDE, ALMA_LONGITUDE, ALMA_HEIGHT, `America/Santiago`, `ALMA`)
+}
+
+func APEX() Observatory {
+	return NewGeodeticObservatory(APEX_LATITUDE, APEX_LONGITUDE, APEX_HEIGHT, `America/Santiago`, `APEX`)
+}
+
 func ASTE() Observatory {
-	return NewObservatory(ASTE_LATITUDE, ASTE_LONGITUDE, `ASTE`)
+	return NewGeodeticObservatory(ASTE_LATITUDE, ASTE_LONGITUDE, ASTE_HEIGHT, `America/Santiago`, `ASTE`)
 }
 
 func Effelsberg() Observatory {
-	return NewObservatory(EFFELSBERG_LATITUDE, EFFELSBERG_LONGITUDE, `EFFELSBERG`)
+	return NewGeodeticObservatory(EFFELSBERG_LATITUDE, EFFELSBERG_LONGITUDE, EFFELSBERG_HEIGHT, `Europe/Berlin`, `EFFELSBERG`)
 }
 
 func GBT() Observatory {
-	return NewObservatory(GBT_LATITUDE, GBT_LONGITUDE, `GBT`)
+	return NewGeodeticObservatory(GBT_LATITUDE, GBT_LONGITUDE, GBT_HEIGHT, `America/New_York`, `GBT`)
+}
+
+func IRAM30m() Observatory {
+	return NewGeodeticObservatory(IRAM30M_LATITUDE, IRAM30M_LONGITUDE, IRAM30M_HEIGHT, `Europe/Madrid`, `IRAM30m`)
+}
+
+func JCMT() Observatory {
+	return NewGeodeticObservatory(JCMT_LATITUDE, JCMT_LONGITUDE, JCMT_HEIGHT, `Pacific/Honolulu`, `JCMT`)
 }
 
 func NRO() Observatory {
-	return NewObservatory(NRO_LATITUDE, NRO_LONGITUDE, `NRO`)
+	return NewGeodeticObservatory(NRO_LATITUDE, NRO_LONGITUDE, NRO_HEIGHT, `Asia/Tokyo`, `NRO`)
+}
+
+func Parkes() Observatory {
+	return NewGeodeticObservatory(PARKES_LATITUDE, PARKES_LONGITUDE, PARKES_HEIGHT, `Australia/Sydney`, `Parkes`)
+}
+
+func SMA() Observatory {
+	return NewGeodeticObservatory(SMA_LATITUDE, SMA_LONGITUDE, SMA_HEIGHT, `Pacific/Honolulu`, `SMA`)
 }
 
 func VLA() Observatory {
-	return NewObservatory(VLA_LATITUDE, VLA_LONGITUDE, `VLA`)
+	return NewGeodeticObservatory(VLA_LATITUDE, VLA_LONGITUDE, VLA_HEIGHT, `America/Denver`, `VLA`)
 }
@@ -0,0 +1,134 @@
+package coordinate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+/* Observatory file */
+// Observatories are read from JSON of the form
+//
+//	{"observatories": [
+//	  {"name": "Site", "aliases": ["S"], "latitude": 35.0, "longitude": 138.0,
+//	   "height": 1350.0, "timezone": "Asia/Tokyo",
+//	   "horizon": [{"azimuth": 0, "elevation": 10}, {"azimuth": 180, "elevation": 5}],
+//	   "refraction": {"model": "radio", "pressure": 870, "temperature": 5, "humidity": 0.5}}
+//	]}
+//
+// with angles in degree (longitude positive to the east) and the height in meter.
+// The refraction model is one of REFRACTION_*, and the conditions default to
+// REFRACTION_PRESSURE, REFRACTION_TEMPERATURE and no humidity.
+type observatoryFile struct {
+	Observatories []observatoryEntry `json:"observatories"`
+}
+
+type observatoryEntry struct {
+	Name       string           `json:"name"`
+	Aliases    []string         `json:"aliases"`
+	Latitude   float64          `json:"latitude"`
+	Longitude  float64          `json:"longitude"`
+	Height     float64          `json:"height"`
+	Timezone   string           `json:"timezone"`
+	Horizon    []HorizonPoint   `json:"horizon"`
+	Refraction *refractionEntry `json:"refraction"`
+}
+
+type refractionEntry struct {
+	Model       string   `json:"model"`
+	Pressure    *float64 `json:"pressure"`
+	Temperature *float64 `json:"temperature"`
+	Humidity    *float64 `json:"humidity"`
+}
+
+// LoadObservatories reads observatories from filename and registers them.
+func LoadObservatories(filename string) ([]Observatory, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	obs, err := ReadObservatories(fp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return obs, nil
+}
+
+// ReadObservatories reads observatories from r and registers them.
+// Nothing is registered if any entry is invalid.
+func ReadObservatories(r io.Reader) ([]Observatory, error) {
+	var file observatoryFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	obs := make([]Observatory, 0, len(file.Observatories))
+	for _, entry := range file.Observatories {
+		if err := entry.validate(); err != nil {
+			return nil, err
+		}
+		tz := entry.Timezone
+		if len(tz) == 0 {
+			tz = `UTC`
+		}
+		o := NewObservatoryWithHorizon(entry.Latitude, entry.Longitude, entry.Height, tz, entry.Name, entry.Horizon)
+		if entry.Refraction != nil {
+			m, err := entry.Refraction.model()
+			if err != nil {
+				return nil, fmt.Errorf("observatory %s: %w", entry.Name, err)
+			}
+			o = WithRefraction(o, m)
+		}
+		obs = append(obs, o)
+	}
+	aliases := make([][]string, len(file.Observatories))
+	for i, entry := range file.Observatories {
+		aliases[i] = entry.Aliases
+	}
+	if err := registerObservatories(obs, aliases); err != nil {
+		return nil, err
+	}
+	return obs, nil
+}
+
+func (e *observatoryEntry) validate() error {
+	if len(e.Name) == 0 {
+		return fmt.Errorf("observatory without name")
+	}
+	if e.Latitude < -90. || 90. < e.Latitude {
+		return fmt.Errorf("observatory %s: latitude %f out of range", e.Name, e.Latitude)
+	}
+	if e.Longitude < -180. || 360. < e.Longitude {
+		return fmt.Errorf("observatory %s: longitude %f out of range", e.Name, e.Longitude)
+	}
+	if len(e.Timezone) != 0 {
+		if _, err := time.LoadLocation(e.Timezone); err != nil {
+			return fmt.Errorf("observatory %s: %w", e.Name, err)
+		}
+	}
+	for _, p := range e.Horizon {
+		if p.Elevation < -90. || 90. < p.Elevation {
+			return fmt.Errorf("observatory %s: horizon elevation %f out of range", e.Name, p.Elevation)
+		}
+	}
+	return nil
+}
+
+func (e *refractionEntry) model() (RefractionModel, error) {
+	pressure, temperature, humidity := REFRACTION_PRESSURE, REFRACTION_TEMPERATURE, 0.
+	if e.Pressure != nil {
+		pressure = *e.Pressure
+	}
+	if e.Temperature != nil {
+		temperature = *e.Temperature
+	}
+	if e.Humidity != nil {
+		humidity = *e.Humidity
+	}
+	return NewRefractionModel(e.Model, pressure, temperature, humidity)
+}
@@ -0,0 +1,67 @@
+package coordinate
+
+import (
+	"strings"
+	"testing"
+)
+
+// unregisterObservatories removes the names registered by a test when it finishes,
+// so that the test can run again in the same process.
+func unregisterObservatories(t *testing.T, names ...string) {
+	t.Cleanup(func() {
+		observatoriesMu.Lock()
+		defer observatoriesMu.Unlock()
+		for _, name := range names {
+			delete(observatories, strings.ToLower(name))
+		}
+	})
+}
+
+func TestReadObservatories(t *testing.T) {
+	unregisterObservatories(t, `TestSiteA`, `TSA`, `TestSiteB`, `TestSiteC`, `TestSiteD`, `TestSiteE`, `TestSiteF`)
+	const valid = `{"name": "TestSiteA", "aliases": ["TSA"], "latitude": 35.0, "longitude": 138.0, "height": 1350.0}`
+	const invalid = `{"name": "TestSiteB", "latitude": 95.0, "longitude": 138.0}`
+	if _, err := ReadObservatories(strings.NewReader(`{"observatories": [` + valid + `, ` + invalid + `]}`)); err == nil {
+		t.Fatal("no error for an invalid latitude")
+	}
+	if _, err := LookupObservatory(`TestSiteA`); err == nil {
+		t.Errorf("registered from an invalid file")
+	}
+
+	obs, err := ReadObservatories(strings.NewReader(`{"observatories": [` + valid + `]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := LookupObservatory(`TSA`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o != obs[0] {
+		t.Errorf("alias resolves to %v", o)
+	}
+
+	// A name taken by a registered observatory leaves the file unregistered.
+	const taken = `{"name": "TestSiteC", "aliases": ["TSA"], "latitude": 35.0, "longitude": 138.0}`
+	const other = `{"name": "TestSiteD", "latitude": 35.0, "longitude": 138.0}`
+	if _, err := ReadObservatories(strings.NewReader(`{"observatories": [` + other + `, ` + taken + `]}`)); err == nil {
+		t.Fatal("no error for a registered alias")
+	}
+	for _, name := range []string{`TestSiteC`, `TestSiteD`} {
+		if _, err := LookupObservatory(name); err == nil {
+			t.Errorf("%s registered from a file with a registered alias", name)
+		}
+	}
+
+	// The refraction model of the site
+	const radio = `{"name": "TestSiteE", "latitude": 35.0, "longitude": 138.0, "refraction": {"model": "radio", "pressure": 550, "humidity": 0.3}}`
+	if obs, err = ReadObservatories(strings.NewReader(`{"observatories": [` + radio + `]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := obs[0].Refraction().(*RadioRefraction); !ok || m.Pressure != 550. || m.Temperature != REFRACTION_TEMPERATURE || m.Humidity != 0.3 {
+		t.Errorf("refraction model %+v", obs[0].Refraction())
+	}
+	const unknown = `{"name": "TestSiteF", "latitude": 35.0, "longitude": 138.0, "refraction": {"model": "unknown"}}`
+	if _, err := ReadObservatories(strings.NewReader(`{"observatories": [` + unknown + `]}`)); err == nil {
+		t.Errorf("no error for an unknown refraction model")
+	}
+}
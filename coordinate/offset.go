@@ -0,0 +1,109 @@
+package coordinate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// Frame family "Offset(system, lon, lat, rotation)" centered on (lon, lat) of system, see OffsetFrame.
+	SYSTEM_OFFSET string = `Offset`
+)
+
+/* Offsets on the sphere */
+// Offset returns the point at the longitude xoff and the latitude yoff in the offset frame
+// centered on s, i.e. xoff and yoff are the offsets along the great circles through s
+// toward the increasing longitude and latitude. It is the inverse of SphericalOffsetsTo.
+func (s *Spherical) Offset(xoff, yoff *Angle) *Spherical {
+	v := (&Spherical{X: xoff, Y: yoff}).ToCartesian()
+	return offsetMatrix(s, 0.).Transpose().Apply(v).ToSpherical().Normalize()
+}
+
+// OffsetBy returns the point at the angular distance sep and the position angle pa from s.
+func (s *Spherical) OffsetBy(sep, pa *Angle) *Spherical {
+	lon, lat := s.X.Radian(), s.Y.Radian()
+	d, p := sep.Radian(), pa.Radian()
+	lat2 := math.Asin(math.Sin(lat)*math.Cos(d) + math.Cos(lat)*math.Sin(d)*math.Cos(p))
+	lon2 := lon + math.Atan2(math.Sin(p)*math.Sin(d)*math.Cos(lat), math.Cos(d)-math.Sin(lat)*math.Sin(lat2))
+	return (&Spherical{X: NewAngle(RadToDeg(lon2)), Y: NewAngle(RadToDeg(lat2))}).Normalize()
+}
+
+// SphericalOffsetsTo returns the longitude and the latitude of other in the offset frame
+// centered on s. The longitude is in (-180, 180] deg.
+func (s *Spherical) SphericalOffsetsTo(other *Spherical) (*Angle, *Angle) {
+	o := offsetMatrix(s, 0.).Apply(other.ToCartesian()).ToSpherical()
+	return o.X, o.Y
+}
+
+// offsetMatrix rotates vectors so that center is moved to (0, 0), and the direction
+// of the position angle rotation [rad] at center is moved to the increasing latitude.
+func offsetMatrix(center *Spherical, rotation float64) Matrix {
+	m := RotationY(-center.Y.Radian()).Multiply(RotationZ(center.X.Radian()))
+	return RotationX(-rotation).Multiply(m)
+}
+
+/* Offset frame */
+// OffsetCoordinate is a coordinate in an offset frame. The longitude is kept in (-180, 180] deg.
+type OffsetCoordinate struct {
+	*coordinate
+}
+
+func (coord *OffsetCoordinate) String() string {
+	return fmt.Sprintf(`%s, dLon: %s, dLat: %s`, coord.system, coord.X.String(`deg`), coord.Y.String(`deg`))
+}
+
+func (coord *OffsetCoordinate) Longitude() *Angle {
+	return coord.X
+}
+
+func (coord *OffsetCoordinate) Latitude() *Angle {
+	return coord.Y
+}
+
+// OffsetFrame returns the name of the frame centered on center, whose latitude axis points
+// toward the position angle rotation at center. Coordinates in the frame are the offsets
+// used for on-the-fly maps and position switching, and can be converted as usual, e.g.
+//
+//	c.ConvertTo(OffsetFrame(center, NewAngle(0.)))
+func OffsetFrame(center Coordinate, rotation *Angle) string {
+	return fmt.Sprintf(`%s(%s,%s,%s,%s)`, SYSTEM_OFFSET, center.System(),
+		formatDegree(center.GetX().Degree()), formatDegree(center.GetY().Degree()), formatDegree(rotation.Degree()))
+}
+
+// offsetFrame builds the frame "Offset(system, lon, lat, rotation)" in degree.
+// The rotation may be omitted.
+func offsetFrame(args []string) (*Frame, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return nil, fmt.Errorf("expected system, longitude, latitude and rotation, got %d arguments", len(args))
+	}
+	parent, err := LookupFrame(args[0])
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, 3)
+	for i, arg := range args[1:] {
+		if values[i], err = strconv.ParseFloat(arg, 64); err != nil {
+			return nil, fmt.Errorf("invalid angle %q", arg)
+		}
+	}
+	center := &Spherical{X: NewAngle(values[0]), Y: NewAngle(values[1])}
+	name := fmt.Sprintf(`%s(%s,%s,%s,%s)`, SYSTEM_OFFSET, parent.Name,
+		formatDegree(values[0]), formatDegree(values[1]), formatDegree(values[2]))
+	f := NewRotationFrame(name, parent.Name, offsetMatrix(center, DegToRad(values[2])))
+	f.Wrap = wrapOffset
+	return f, nil
+}
+
+func wrapOffset(c Coordinate) Coordinate {
+	cc := c.(*coordinate)
+	x := cc.X.Degree()
+	if x > 180. {
+		x -= 360.
+	}
+	return &OffsetCoordinate{&coordinate{Spherical: &Spherical{X: NewAngle(x), Y: cc.Y}, system: cc.system}}
+}
+
+func formatDegree(deg float64) string {
+	return strconv.FormatFloat(deg, 'f', -1, 64)
+}
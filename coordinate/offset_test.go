@@ -0,0 +1,22 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSphericalOffsetsTo(t *testing.T) {
+	// The offsets of a point in another system are taken in the system of the center.
+	center := NewCoordinate(SYSTEM_J2000, 83.633, 22.014)
+	other, err := center.Offset(NewAngle(0.3), NewAngle(-0.2)).Convert(SYSTEM_GAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lon, lat, err := center.SphericalOffsetsTo(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(lon.Degree()-0.3) > 1e-7 || math.Abs(lat.Degree()+0.2) > 1e-7 {
+		t.Errorf("offsets (%g, %g) deg", lon.Degree(), lat.Degree())
+	}
+}
@@ -0,0 +1,128 @@
+package coordinate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+const (
+	PLANET_MERCURY string = `Mercury`
+	PLANET_VENUS   string = `Venus`
+	PLANET_MARS    string = `Mars`
+	PLANET_JUPITER string = `Jupiter`
+	PLANET_SATURN  string = `Saturn`
+	PLANET_URANUS  string = `Uranus`
+	PLANET_NEPTUNE string = `Neptune`
+)
+
+var (
+	ErrUnknownPlanet = errors.New(`unknown planet`)
+
+	// NAIF codes of the planets (barycenters of the planetary systems)
+	planetNAIF = map[string]int{
+		PLANET_MERCURY: 1,
+		PLANET_VENUS:   2,
+		PLANET_MARS:    4,
+		PLANET_JUPITER: 5,
+		PLANET_SATURN:  6,
+		PLANET_URANUS:  7,
+		PLANET_NEPTUNE: 8,
+	}
+)
+
+/* Planets */
+// Planet returns the astrometric position of the planet name (case-insensitive) at t in system,
+// seen from o, or from the geocenter if o is nil. The light time is corrected, so that
+// the position can be passed to ApparentPlace or ToAltAz like a catalog position.
+// The ephemeris set with SetEphemeris is used if any, and it must cover t. Otherwise the planet
+// follows the truncated VSOP87, accurate to about 1 arcsec for the inner planets and a few arcsec
+// for the outer ones.
+func Planet(name string, o Observatory, t astrotime.Instant, system string) (Coordinate, error) {
+	name = canonicalPlanet(name)
+	series, ok := planetSeries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownPlanet, name)
+	}
+	if e := currentEphemeris(); e != nil {
+		p, err := ephemerisPosition(e, planetNAIF[name], o, t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return icrsVectorTo(p, system)
+	}
+	return icrsVectorTo(planetPosition(series.icrs, o, t), system)
+}
+
+// icrsVectorTo returns the direction of the vector v in ICRS as a coordinate of system.
+func icrsVectorTo(v *Cartesian, system string) (Coordinate, error) {
+	icrs, err := CoordinateOfSphere(SYSTEM_ICRS, v.ToSpherical().Normalize())
+	if err != nil {
+		return nil, err
+	}
+	return icrs.Convert(system)
+}
+
+// Planets returns the names of the supported planets.
+func Planets() []string {
+	return []string{PLANET_MERCURY, PLANET_VENUS, PLANET_MARS, PLANET_JUPITER, PLANET_SATURN, PLANET_URANUS, PLANET_NEPTUNE}
+}
+
+func canonicalPlanet(name string) string {
+	for _, planet := range Planets() {
+		if strings.EqualFold(planet, name) {
+			return planet
+		}
+	}
+	return name
+}
+
+// planetPosition returns the vector [AU] in ICRS from the observer at t to the body,
+// whose heliocentric position [AU] in ICRS at jd (TDB) is given by heliocentric,
+// at the time the light left it.
+func planetPosition(heliocentric func(float64) *Cartesian, o Observatory, t astrotime.Instant) *Cartesian {
+	jd := t.JD(astrotime.TDB)
+	observer := earthICRS(t.JD(astrotime.TT))
+	if o != nil {
+		r, _ := observatoryGCRS(o, t)
+		observer = observer.Add(r.Scale(1. / AU_METER))
+	}
+	p := heliocentric(jd).Sub(observer)
+	for i := 0; i < 3; i++ {
+		p = heliocentric(jd - p.Norm()*LIGHT_TIME_AU_DAY).Sub(observer)
+	}
+	return p
+}
+
+// ephemerisPosition is the same as planetPosition for the body target of the ephemeris e.
+func ephemerisPosition(e Ephemeris, target int, o Observatory, t astrotime.Instant) (*Cartesian, error) {
+	jd := t.JD(astrotime.TDB)
+	observer, _, err := barycentricState(e, NAIF_EARTH, jd)
+	if err != nil {
+		return nil, err
+	}
+	if o != nil {
+		r, _ := observatoryGCRS(o, t)
+		observer = observer.Add(r.Scale(1. / AU_METER))
+	}
+	lt := 0.
+	for i := 0; i < 4; i++ {
+		p, _, err := barycentricState(e, target, jd-lt)
+		if err != nil {
+			return nil, err
+		}
+		lt = p.Sub(observer).Norm() * LIGHT_TIME_AU_DAY
+	}
+	p, _, err := barycentricState(e, target, jd-lt)
+	if err != nil {
+		return nil, err
+	}
+	return p.Sub(observer), nil
+}
+
+// earthICRS returns the heliocentric position [AU] of the Earth in ICRS at jd (TT) from VSOP87.
+func earthICRS(jd float64) *Cartesian {
+	return earthSeries.icrs(jd)
+}
@@ -0,0 +1,61 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+// Meeus, Astronomical Algorithms, example 33.a (Venus, 1992 December 20.0 TD):
+// the light-time corrected geocentric position in the ecliptic and equinox of date
+func TestPlanet(t *testing.T) {
+	ti, err := astrotime.FromJD(2448976.5, astrotime.TT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Planet(`venus`, nil, ti, EclipticOfDate(ti.JD(astrotime.TT)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := (c.GetX().Degree() - 313.08102) * 3600.; math.Abs(d) > 2. {
+		t.Errorf("longitude off by %g arcsec", d)
+	}
+	if d := (c.GetY().Degree() - (-2.08474)) * 3600.; math.Abs(d) > 2. {
+		t.Errorf("latitude off by %g arcsec", d)
+	}
+	if _, err := Planet(`Pluto`, nil, ti, SYSTEM_ICRS); err == nil {
+		t.Errorf("no error for an unknown planet")
+	}
+}
+
+// Meeus, Astronomical Algorithms, example 32.a (Venus, 1992 December 20.0 TD)
+func TestPlanetHeliocentric(t *testing.T) {
+	venus := planetSeries[PLANET_VENUS]
+	l, b, r := venus.heliocentric(2448976.5)
+	if d := RadToDeg(l) - 26.11428; math.Abs(d) > 1e-4 {
+		t.Errorf("L off by %g deg", d)
+	}
+	if d := RadToDeg(b) - (-2.62070); math.Abs(d) > 1e-4 {
+		t.Errorf("B off by %g deg", d)
+	}
+	if d := r - 0.724603; math.Abs(d) > 1e-6 {
+		t.Errorf("R off by %g AU", d)
+	}
+}
+
+func TestPlanetSeries(t *testing.T) {
+	for _, name := range Planets() {
+		if _, ok := planetSeries[name]; !ok {
+			t.Errorf("no series for %s", name)
+		}
+	}
+}
+
+func TestSunBarycentric(t *testing.T) {
+	// DE405 at J2000: the Sun is 0.0077 AU from the barycenter, mostly pulled by Jupiter.
+	want := &Cartesian{X: -0.00712, Y: -0.00264, Z: -0.00092}
+	if d := sunBarycentric(JD_J2000).Sub(want).Norm(); d > 5e-5 {
+		t.Errorf("off by %g AU", d)
+	}
+}
@@ -0,0 +1,45 @@
+package coordinate
+
+/* Precession (IAU 2006, Capitaine et al. 2003) */
+
+func julianCenturies(jd float64) float64 {
+	return (jd - JD_J2000) / DAYS_PER_JULIAN_CENTURY
+}
+
+// MeanObliquity returns the mean obliquity of the ecliptic at jd (TT) in radian.
+func MeanObliquity(jd float64) float64 {
+	t := julianCenturies(jd)
+	return ArcsecToRad(84381.406 + t*(-46.836769+t*(-0.0001831+t*(0.00200340+t*(-0.000000576+t*-0.0000000434)))))
+}
+
+// PrecessionMatrix returns the rotation from the mean equator and equinox of J2000
+// to the mean equator and equinox of jd (TT).
+func PrecessionMatrix(jd float64) Matrix {
+	t := julianCenturies(jd)
+	zeta := 2.650545 + t*(2306.083227+t*(0.2988499+t*(0.01801828+t*(-0.000005971+t*-0.0000003173))))
+	z := -2.650545 + t*(2306.077181+t*(1.0927348+t*(0.01826837+t*(-0.000028596+t*-0.0000002904))))
+	theta := t * (2004.191903 + t*(-0.4294934+t*(-0.04182264+t*(-0.000007089+t*-0.0000001274))))
+	return RotationZ(-ArcsecToRad(z)).Multiply(RotationY(ArcsecToRad(theta))).Multiply(RotationZ(-ArcsecToRad(zeta)))
+}
+
+// PrecessionMatrixIAU1976 returns the rotation from the mean equator and equinox of J2000
+// to that of jd (TT) with the IAU 1976 (Lieske) precession, which defines FK5.
+func PrecessionMatrixIAU1976(jd float64) Matrix {
+	t := julianCenturies(jd)
+	zeta := t * (2306.2181 + t*(0.30188+t*0.017998))
+	z := t * (2306.2181 + t*(1.09468+t*0.018203))
+	theta := t * (2004.3109 + t*(-0.42665+t*-0.041833))
+	return RotationZ(-ArcsecToRad(z)).Multiply(RotationY(ArcsecToRad(theta))).Multiply(RotationZ(-ArcsecToRad(zeta)))
+}
+
+// PrecessionMatrixNewcomb returns the rotation between the mean equators and equinoxes
+// of two Besselian epochs given as Julian dates, with the Newcomb precession used in FK4.
+func PrecessionMatrixNewcomb(from, to float64) Matrix {
+	t1 := (JDToBesselianEpoch(from) - 1850.) / 1000.
+	t := (JDToBesselianEpoch(to)-1850.)/1000. - t1
+	zeta1 := 23035.545 + t1*(139.720+t1*0.060)
+	zeta := t * (zeta1 + t*((30.240-0.27*t1)+t*17.995))
+	z := t * (zeta1 + t*((109.480+0.39*t1)+t*18.325))
+	theta := t * ((20051.12 - t1*(85.29+t1*0.37)) + t*((-42.65-0.37*t1)+t*-41.8))
+	return RotationZ(-ArcsecToRad(z)).Multiply(RotationY(ArcsecToRad(theta))).Multiply(RotationZ(-ArcsecToRad(zeta)))
+}
@@ -0,0 +1,206 @@
+package coordinate
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// Standard conditions of the optical formulae
+	REFRACTION_PRESSURE    float64 = 1010. // hPa
+	REFRACTION_TEMPERATURE float64 = 10.   // degC
+
+	/* Refraction models */
+	REFRACTION_BENNETT     string = `bennett`
+	REFRACTION_SAEMUNDSSON string = `saemundsson`
+	REFRACTION_RADIO       string = `radio`
+	REFRACTION_NONE        string = `none`
+)
+
+/* Atmospheric refraction */
+// RefractionModel converts between the geometric and the observed (refracted) elevation.
+// Each Observatory has one, which is Bennett under the standard conditions unless set
+// with WithRefraction. The functions taking a model use that of the observatory if it is nil.
+type RefractionModel interface {
+	Refract(*Angle) *Angle   // geometric -> observed
+	Unrefract(*Angle) *Angle // observed -> geometric
+}
+
+// Bennett (1982) refraction for optical wavelengths,
+// given for the observed elevation. Pressure in hPa and temperature in degC.
+type Bennett struct {
+	Pressure    float64
+	Temperature float64
+}
+
+// Saemundsson (1986) refraction for optical wavelengths,
+// given for the geometric elevation. Pressure in hPa and temperature in degC.
+type Saemundsson struct {
+	Pressure    float64
+	Temperature float64
+}
+
+// RadioRefraction scales the Bennett formula by the radio refractivity
+// (Smith & Weintraub 1953), which depends on the water vapour.
+// Pressure in hPa, temperature in degC and relative humidity from 0 to 1.
+type RadioRefraction struct {
+	Pressure    float64
+	Temperature float64
+	Humidity    float64
+}
+
+// NoRefraction leaves the elevation geometric.
+type NoRefraction struct{}
+
+// NewRefractionModel returns the model named one of REFRACTION_*. Pressure in hPa,
+// temperature in degC and relative humidity from 0 to 1, which only the radio model uses.
+func NewRefractionModel(model string, pressure, temperature, humidity float64) (RefractionModel, error) {
+	if pressure < 0. || temperature <= -273.15 || humidity < 0. || 1. < humidity {
+		return nil, fmt.Errorf("invalid refraction conditions: %g hPa, %g degC, humidity %g", pressure, temperature, humidity)
+	}
+	switch model {
+	case REFRACTION_BENNETT:
+		return &Bennett{Pressure: pressure, Temperature: temperature}, nil
+	case REFRACTION_SAEMUNDSSON:
+		return &Saemundsson{Pressure: pressure, Temperature: temperature}, nil
+	case REFRACTION_RADIO:
+		return NewRadioRefraction(pressure, temperature, humidity), nil
+	case REFRACTION_NONE:
+		return NoRefraction{}, nil
+	}
+	return nil, fmt.Errorf("unknown refraction model %q", model)
+}
+
+func NewBennett() *Bennett {
+	return &Bennett{Pressure: REFRACTION_PRESSURE, Temperature: REFRACTION_TEMPERATURE}
+}
+
+func NewSaemundsson() *Saemundsson {
+	return &Saemundsson{Pressure: REFRACTION_PRESSURE, Temperature: REFRACTION_TEMPERATURE}
+}
+
+func NewRadioRefraction(pressure, temperature, humidity float64) *RadioRefraction {
+	return &RadioRefraction{Pressure: pressure, Temperature: temperature, Humidity: humidity}
+}
+
+func (NoRefraction) Refract(el *Angle) *Angle {
+	return el
+}
+
+func (NoRefraction) Unrefract(el *Angle) *Angle {
+	return el
+}
+
+func (m *Bennett) Refract(el *Angle) *Angle {
+	return refractByIteration(el, m.Unrefract)
+}
+
+func (m *Bennett) Unrefract(el *Angle) *Angle {
+	r := bennett(el.Degree()) * conditionFactor(m.Pressure, m.Temperature)
+	return NewAngle(el.Degree() - r)
+}
+
+func (m *Saemundsson) Refract(el *Angle) *Angle {
+	h := el.Degree()
+	return NewAngle(h + saemundsson(h)*conditionFactor(m.Pressure, m.Temperature))
+}
+
+func (m *Saemundsson) Unrefract(el *Angle) *Angle {
+	return unrefractByIteration(el, m.Refract)
+}
+
+func (m *RadioRefraction) Refract(el *Angle) *Angle {
+	return refractByIteration(el, m.Unrefract)
+}
+
+func (m *RadioRefraction) Unrefract(el *Angle) *Angle {
+	// Bennett's formula corresponds to (n-1) cot(el) at high elevation with n-1 = R(45 deg)
+	r := bennett(el.Degree()) * m.Refractivity() * 1e-6 / DegToRad(bennett(45.))
+	return NewAngle(el.Degree() - r)
+}
+
+// Refractivity returns N = (n-1)*1e6 at radio wavelengths.
+func (m *RadioRefraction) Refractivity() float64 {
+	t := m.Temperature + 273.15
+	// saturation vapour pressure over water (Buck 1981) in hPa
+	es := 6.1121 * math.Exp(17.502*m.Temperature/(240.97+m.Temperature))
+	e := m.Humidity * es
+	return 77.6 / t * (m.Pressure + 4810.*e/t)
+}
+
+// bennett returns the refraction [deg] at the observed elevation h [deg] under the standard conditions.
+func bennett(h float64) float64 {
+	if h < -1. {
+		h = -1.
+	}
+	return 1. / math.Tan(DegToRad(h+7.31/(h+4.4))) / 60.
+}
+
+// saemundsson returns the refraction [deg] at the geometric elevation h [deg] under the standard conditions.
+func saemundsson(h float64) float64 {
+	if h < -1. {
+		h = -1.
+	}
+	return 1.02 / math.Tan(DegToRad(h+10.3/(h+5.11))) / 60.
+}
+
+func conditionFactor(pressure, temperature float64) float64 {
+	return pressure / REFRACTION_PRESSURE * (273.15 + REFRACTION_TEMPERATURE) / (273.15 + temperature)
+}
+
+// refractByIteration inverts unrefract, which maps observed to geometric elevations.
+func refractByIteration(el *Angle, unrefract func(*Angle) *Angle) *Angle {
+	observed := el.Degree()
+	for i := 0; i < 10; i++ {
+		d := el.Degree() - unrefract(NewAngle(observed)).Degree()
+		observed += d
+		if math.Abs(d) < 1e-10 {
+			break
+		}
+	}
+	return NewAngle(observed)
+}
+
+// unrefractByIteration inverts refract, which maps geometric to observed elevations.
+func unrefractByIteration(el *Angle, refract func(*Angle) *Angle) *Angle {
+	geometric := el.Degree()
+	for i := 0; i < 10; i++ {
+		d := el.Degree() - refract(NewAngle(geometric)).Degree()
+		geometric += d
+		if math.Abs(d) < 1e-10 {
+			break
+		}
+	}
+	return NewAngle(geometric)
+}
+
+// ObservedElevation is the same as Elevation, but includes the refraction model m,
+// or that of o if m is nil.
+func ObservedElevation(lst *Angle, c Coordinate, o Observatory, m RefractionModel) float64 {
+	return Refract(refractionAt(o, m), NewAngle(Elevation(lst, c, o))).Degree()
+}
+
+// refractionAt returns m, or the refraction model of o if m is nil.
+func refractionAt(o Observatory, m RefractionModel) RefractionModel {
+	if m == nil {
+		return o.Refraction()
+	}
+	return m
+}
+
+// Refract returns the observed elevation of the geometric elevation el with the model m.
+// It returns el itself if m is nil.
+func Refract(m RefractionModel, el *Angle) *Angle {
+	if m == nil {
+		return el
+	}
+	return m.Refract(el)
+}
+
+// Unrefract returns the geometric elevation of the observed elevation el with the model m.
+func Unrefract(m RefractionModel, el *Angle) *Angle {
+	if m == nil {
+		return el
+	}
+	return m.Unrefract(el)
+}
@@ -0,0 +1,60 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBennett(t *testing.T) {
+	// about 34.5 arcmin at the horizon and 1 arcmin at 45 deg
+	m := NewBennett()
+	for _, c := range []struct{ el, r float64 }{{0., 34.48}, {45., 0.99}} {
+		r := (c.el - m.Unrefract(NewAngle(c.el)).Degree()) * 60.
+		if math.Abs(r-c.r) > 0.01 {
+			t.Errorf("refraction at %g deg = %.2f arcmin", c.el, r)
+		}
+	}
+}
+
+func TestRefractionRoundTrip(t *testing.T) {
+	models := map[string]RefractionModel{
+		`Bennett`:     NewBennett(),
+		`Saemundsson`: NewSaemundsson(),
+		`radio`:       NewRadioRefraction(1013.25, 0., 0.5),
+	}
+	for name, m := range models {
+		for _, el := range []float64{0., 5., 30., 89.} {
+			back := m.Unrefract(m.Refract(NewAngle(el))).Degree()
+			if math.Abs(back-el) > 1e-7 {
+				t.Errorf("%s: %g deg -> %g deg", name, el, back)
+			}
+		}
+		if r := m.Refract(NewAngle(-5.)).Degree() + 5.; r <= 0. || r > 1. {
+			t.Errorf("%s: refraction %g deg below the horizon", name, r)
+		}
+	}
+}
+
+func TestObservatoryRefraction(t *testing.T) {
+	lst, c := NewAngle(0.), NewCoordinate(SYSTEM_J2000, 60., 0.)
+	o := NRO()
+	geometric := Elevation(lst, c, o)
+	bennett := NewBennett().Refract(NewAngle(geometric)).Degree()
+	if el := ObservedElevation(lst, c, o, nil); el != bennett {
+		t.Errorf("default model: %g deg, want %g", el, bennett)
+	}
+	radio := NewRadioRefraction(550., -5., 0.3)
+	site := WithRefraction(o, radio)
+	if el, want := ObservedElevation(lst, c, site, nil), radio.Refract(NewAngle(geometric)).Degree(); el != want {
+		t.Errorf("site model: %g deg, want %g", el, want)
+	}
+	if el := ObservedElevation(lst, c, site, NoRefraction{}); el != geometric {
+		t.Errorf("override: %g deg, want %g", el, geometric)
+	}
+	if _, ok := o.Refraction().(*Bennett); !ok {
+		t.Errorf("the copy changed the model of the original")
+	}
+	if _, err := NewRefractionModel(`unknown`, REFRACTION_PRESSURE, REFRACTION_TEMPERATURE, 0.); err == nil {
+		t.Errorf("no error for an unknown model")
+	}
+}
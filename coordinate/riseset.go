@@ -0,0 +1,179 @@
+package coordinate
+
+import (
+	"math"
+	"time"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+const (
+	// Time resolution of the rise, transit and set times
+	RISESET_STEP      time.Duration = 10 * time.Minute
+	RISESET_PRECISION time.Duration = time.Second
+)
+
+/* Rise, transit and set */
+// PositionFunc returns the position of a target at t, e.g. of a planet or the Moon.
+type PositionFunc func(astrotime.Instant) (Coordinate, error)
+
+// FixedPosition returns the PositionFunc of a target at the fixed position c.
+func FixedPosition(c Coordinate) PositionFunc {
+	return func(astrotime.Instant) (Coordinate, error) {
+		return c, nil
+	}
+}
+
+// RiseSet holds the events of a target during a day. Rise and Set are the first
+// crossings of the elevation limit in the day, and Transit is the time of the highest
+// elevation. An event that does not occur in the day is nil.
+type RiseSet struct {
+	Rise             *astrotime.Instant
+	Transit          *astrotime.Instant
+	Set              *astrotime.Instant
+	TransitElevation float64
+	Circumpolar      bool // above the limit during the whole day
+	NeverRises       bool // below the limit during the whole day
+}
+
+// RiseTransitSet returns the rise, transit and set of c seen from o in the 24 hours from date.
+// limit is the observed elevation in degree with the refraction model m (that of o if nil;
+// NoRefraction for the geometric one) above the horizon mask of o toward the target.
+func RiseTransitSet(c Coordinate, o Observatory, date astrotime.Instant, limit float64, m RefractionModel) (*RiseSet, error) {
+	return RiseTransitSetOf(FixedPosition(c), o, date, limit, m)
+}
+
+// RiseTransitSetOf is the same as RiseTransitSet for a moving target.
+func RiseTransitSetOf(position PositionFunc, o Observatory, date astrotime.Instant, limit float64, m RefractionModel) (*RiseSet, error) {
+	elevation := func(t astrotime.Instant) (float64, error) {
+		return ObservedElevationAt(position, o, t, m)
+	}
+	// the observed elevation above the horizon mask
+	height := func(t astrotime.Instant) (float64, error) {
+		h, err := altAzAt(position, o, t)
+		if err != nil {
+			return 0., err
+		}
+		return h.ObservedElevation(m).Degree() - o.Horizon(h.Az).Degree(), nil
+	}
+	n := int(24 * time.Hour / RISESET_STEP)
+	times := make([]astrotime.Instant, n+1)
+	els := make([]float64, n+1)
+	heights := make([]float64, n+1)
+	for i := range times {
+		times[i] = date.Add(time.Duration(i) * RISESET_STEP)
+		h, err := altAzAt(position, o, times[i])
+		if err != nil {
+			return nil, err
+		}
+		els[i] = h.ObservedElevation(m).Degree()
+		heights[i] = els[i] - o.Horizon(h.Az).Degree()
+	}
+
+	rs := &RiseSet{Circumpolar: true, NeverRises: true}
+	top := 0
+	for i := range els {
+		if els[i] > els[top] {
+			top = i
+		}
+		if heights[i] >= limit {
+			rs.NeverRises = false
+		} else {
+			rs.Circumpolar = false
+		}
+	}
+	for i := 0; i < n; i++ {
+		below, above := heights[i] < limit, heights[i+1] < limit
+		if below == above {
+			continue
+		}
+		t, err := findCrossing(height, times[i], times[i+1], limit)
+		if err != nil {
+			return nil, err
+		}
+		if below && rs.Rise == nil {
+			rs.Rise = &t
+		}
+		if !below && rs.Set == nil {
+			rs.Set = &t
+		}
+	}
+
+	// The highest elevation at the ends of the day is not a transit.
+	if top > 0 && top < n {
+		t, el, err := findMaximum(elevation, times[top-1], times[top+1])
+		if err != nil {
+			return nil, err
+		}
+		rs.Transit = &t
+		rs.TransitElevation = el
+	}
+	return rs, nil
+}
+
+// ObservedElevationAt returns the observed elevation [deg] of the target at t seen from o,
+// including aberration and the refraction model m, or that of o if m is nil.
+func ObservedElevationAt(position PositionFunc, o Observatory, t astrotime.Instant, m RefractionModel) (float64, error) {
+	h, err := altAzAt(position, o, t)
+	if err != nil {
+		return 0., err
+	}
+	return h.ObservedElevation(m).Degree(), nil
+}
+
+// altAzAt returns the horizontal coordinate of the target at t seen from o.
+func altAzAt(position PositionFunc, o Observatory, t astrotime.Instant) (*AltAz, error) {
+	c, err := position(t)
+	if err != nil {
+		return nil, err
+	}
+	return ToAltAz(c, o, t)
+}
+
+// findCrossing returns the time between t1 and t2 where the elevation crosses limit, by bisection.
+// The returned time is just after the crossing, within RISESET_PRECISION.
+func findCrossing(elevation func(astrotime.Instant) (float64, error), t1, t2 astrotime.Instant, limit float64) (astrotime.Instant, error) {
+	e1, err := elevation(t1)
+	if err != nil {
+		return t1, err
+	}
+	for t2.Sub(t1) > RISESET_PRECISION {
+		mid := t1.Add(t2.Sub(t1) / 2)
+		e, err := elevation(mid)
+		if err != nil {
+			return mid, err
+		}
+		if (e < limit) == (e1 < limit) {
+			t1, e1 = mid, e
+		} else {
+			t2 = mid
+		}
+	}
+	return t2, nil
+}
+
+// findMaximum returns the time and the elevation of the highest point between t1 and t2
+// by the golden section search.
+func findMaximum(elevation func(astrotime.Instant) (float64, error), t1, t2 astrotime.Instant) (astrotime.Instant, float64, error) {
+	r := (math.Sqrt(5.) - 1.) / 2.
+	for t2.Sub(t1) > RISESET_PRECISION {
+		d := time.Duration(float64(t2.Sub(t1)) * r)
+		a, b := t2.Add(-d), t1.Add(d)
+		ea, err := elevation(a)
+		if err != nil {
+			return a, 0., err
+		}
+		eb, err := elevation(b)
+		if err != nil {
+			return b, 0., err
+		}
+		if ea < eb {
+			t1 = a
+		} else {
+			t2 = b
+		}
+	}
+	t := t1.Add(t2.Sub(t1) / 2)
+	el, err := elevation(t)
+	return t, el, err
+}
@@ -0,0 +1,36 @@
+package coordinate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+func TestRiseTransitSetHorizon(t *testing.T) {
+	// A flat mask at 10 deg is the same as the limit of 10 deg without a mask.
+	c := NewCoordinate(SYSTEM_J2000, 30., 0.)
+	date := astrotime.FromTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	flat := NewObservatoryWithHorizon(NRO_LATITUDE, NRO_LONGITUDE, NRO_HEIGHT, `Asia/Tokyo`, `flat`,
+		[]HorizonPoint{{Azimuth: 0., Elevation: 10.}, {Azimuth: 180., Elevation: 10.}})
+	masked, err := RiseTransitSet(c, flat, date, 0., nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := RiseTransitSet(c, NRO(), date, 10., nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if masked.Rise == nil || masked.Set == nil || want.Rise == nil || want.Set == nil {
+		t.Fatalf("no rise or set: %+v, %+v", masked, want)
+	}
+	if d := masked.Rise.Sub(*want.Rise); d < -2*RISESET_PRECISION || d > 2*RISESET_PRECISION {
+		t.Errorf("rise off by %s", d)
+	}
+	if d := masked.Set.Sub(*want.Set); d < -2*RISESET_PRECISION || d > 2*RISESET_PRECISION {
+		t.Errorf("set off by %s", d)
+	}
+	if masked.TransitElevation != want.TransitElevation {
+		t.Errorf("transit elevation %g deg, want %g", masked.TransitElevation, want.TransitElevation)
+	}
+}
@@ -0,0 +1,45 @@
+package coordinate
+
+import (
+	"math"
+)
+
+/* Angular distance */
+// Separation returns the angular distance between a and b, converting b into the system of a.
+// The Vincenty formula is used, which is stable for both tiny and nearly antipodal separations.
+func Separation(a, b Coordinate) (*Angle, error) {
+	lon1, lat1, lon2, lat2, err := commonAngles(a, b)
+	if err != nil {
+		return nil, err
+	}
+	dlon := lon2 - lon1
+	num1 := math.Cos(lat2) * math.Sin(dlon)
+	num2 := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlon)
+	den := math.Sin(lat1)*math.Sin(lat2) + math.Cos(lat1)*math.Cos(lat2)*math.Cos(dlon)
+	return NewAngle(RadToDeg(math.Atan2(math.Hypot(num1, num2), den))), nil
+}
+
+// PositionAngle returns the position angle of b seen from a, measured from the north
+// toward the increasing longitude (east for equatorial systems), in [0, 360) deg.
+// b is converted into the system of a.
+func PositionAngle(a, b Coordinate) (*Angle, error) {
+	lon1, lat1, lon2, lat2, err := commonAngles(a, b)
+	if err != nil {
+		return nil, err
+	}
+	dlon := lon2 - lon1
+	y := math.Sin(dlon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlon)
+	return NewAngle(normalizeDegree(RadToDeg(math.Atan2(y, x)))), nil
+}
+
+// commonAngles returns the longitudes and latitudes [rad] of a and b in the system of a.
+func commonAngles(a, b Coordinate) (float64, float64, float64, float64, error) {
+	if b.System() != a.System() {
+		var err error
+		if b, err = b.Convert(a.System()); err != nil {
+			return 0., 0., 0., 0., err
+		}
+	}
+	return a.GetX().Radian(), a.GetY().Radian(), b.GetX().Radian(), b.GetY().Radian(), nil
+}
@@ -0,0 +1,43 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSeparation(t *testing.T) {
+	cases := []struct{ x1, y1, x2, y2, sep float64 }{
+		{10., 20., 10., 20. + 1e-9, 1e-9},
+		{0., 0., 90., 0., 90.},
+		{0., 0., 180. - 1e-7, 0., 180. - 1e-7},
+		{0., 89., 180., 89., 2.},
+	}
+	for _, c := range cases {
+		sep, err := Separation(NewCoordinate(SYSTEM_J2000, c.x1, c.y1), NewCoordinate(SYSTEM_J2000, c.x2, c.y2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(sep.Degree()-c.sep) > 1e-12 {
+			t.Errorf("(%g, %g)-(%g, %g) = %.15g deg", c.x1, c.y1, c.x2, c.y2, sep.Degree())
+		}
+	}
+}
+
+func TestPositionAngle(t *testing.T) {
+	a := NewCoordinate(SYSTEM_J2000, 100., 10.)
+	cases := []struct{ x, y, pa float64 }{
+		{100., 11., 0.},
+		{101., 10., 90.},
+		{100., 9., 180.},
+		{99., 10., 270.},
+	}
+	for _, c := range cases {
+		pa, err := PositionAngle(a, NewCoordinate(SYSTEM_J2000, c.x, c.y))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(pa.Degree()-c.pa) > 0.1 {
+			t.Errorf("(%g, %g) = %g deg", c.x, c.y, pa.Degree())
+		}
+	}
+}
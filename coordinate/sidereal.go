@@ -0,0 +1,54 @@
+package coordinate
+
+import (
+	"math"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+/* Sidereal time */
+// EarthRotationAngle returns the Earth rotation angle (IAU 2000) at t.
+func EarthRotationAngle(t astrotime.Instant) *Angle {
+	jd1, jd2 := t.JD2(astrotime.UT1)
+	_, f1 := math.Modf(jd1)
+	_, f2 := math.Modf(jd2)
+	tu := (jd1 - JD_J2000) + jd2
+	era := math.Mod(f1+f2+0.7790572732640+0.00273781191135448*tu, 1.)
+	return NewAngle(normalizeDegree(era * 360.))
+}
+
+// GMST returns the Greenwich mean sidereal time (IAU 2006) at t.
+func GMST(t astrotime.Instant) *Angle {
+	tt := julianCenturies(t.JD(astrotime.TT))
+	arcsec := 0.014506 + tt*(4612.156534+tt*(1.3915817+tt*(-0.00000044+tt*(-0.000029956+tt*-0.0000000368))))
+	return NewAngle(normalizeDegree(EarthRotationAngle(t).Degree() + arcsec/3600.))
+}
+
+// GAST returns the Greenwich apparent sidereal time at t.
+func GAST(t astrotime.Instant) *Angle {
+	return NewAngle(normalizeDegree(GMST(t).Degree() + RadToDeg(EquationOfEquinoxes(t))))
+}
+
+// EquationOfEquinoxes returns GAST-GMST in radian, including the largest complementary terms.
+func EquationOfEquinoxes(t astrotime.Instant) float64 {
+	jd := t.JD(astrotime.TT)
+	dpsi, _ := Nutation(jd)
+	omega := fundamentalArguments(jd)[4]
+	return dpsi*math.Cos(MeanObliquity(jd)) + ArcsecToRad(0.00264096*math.Sin(omega)+0.00006352*math.Sin(2.*omega))
+}
+
+// LMST returns the local mean sidereal time of the observatory o at t.
+func LMST(o Observatory, t astrotime.Instant) *Angle {
+	return NewAngle(normalizeDegree(GMST(t).Degree() + o.Longitude().Degree()))
+}
+
+// LST returns the local apparent sidereal time of the observatory o at t.
+// This is the lst expected by Elevation.
+func LST(o Observatory, t astrotime.Instant) *Angle {
+	return NewAngle(normalizeDegree(GAST(t).Degree() + o.Longitude().Degree()))
+}
+
+// ElevationAt is the same as Elevation, but computes LST of o at t.
+func ElevationAt(t astrotime.Instant, c Coordinate, o Observatory) float64 {
+	return Elevation(LST(o, t), c, o)
+}
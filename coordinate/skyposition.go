@@ -0,0 +1,265 @@
+package coordinate
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+const (
+	PARSEC_AU float64 = 206264.80624709636
+	// Distance used for the space motion of sources without a parallax (1e-7 arcsec, as SOFA)
+	MIN_PARALLAX float64 = 1e-4 // mas
+	// Radial velocity [km/s] times parallax [arcsec] in the FK4 <-> FK5 space motion,
+	// whose unit is arcsec per tropical century
+	FK4_VELOCITY_FACTOR float64 = 21.095
+)
+
+/* Space motion */
+// SkyPosition is a catalog position with its space motion at Epoch (JD).
+// PmX is the proper motion along the longitude (e.g. RA) including the cos(latitude) factor,
+// and PmY is along the latitude, both in mas/yr. Parallax is in mas and RadialVelocity in km/s.
+// Zero values mean that the quantity is unknown (or negligible).
+type SkyPosition struct {
+	Coord          Coordinate
+	PmX            float64
+	PmY            float64
+	Parallax       float64
+	RadialVelocity float64
+	Epoch          float64
+}
+
+func NewSkyPosition(c Coordinate, epoch float64) *SkyPosition {
+	return &SkyPosition{Coord: c, Epoch: epoch}
+}
+
+func (p *SkyPosition) String() string {
+	return fmt.Sprintf(`%s, pm: (%g, %g) mas/yr, parallax: %g mas, rv: %g km/s, epoch: %s`,
+		p.Coord, p.PmX, p.PmY, p.Parallax, p.RadialVelocity, FormatJulianEpoch(p.Epoch))
+}
+
+// Propagate returns the position at epoch (JD), moving the source along a straight line
+// in space, so that the perspective acceleration and the change of the parallax and the
+// radial velocity are included. The light time is ignored, as in the Hipparcos catalog.
+func (p *SkyPosition) Propagate(epoch float64) (*SkyPosition, error) {
+	plx := p.Parallax
+	if plx <= 0 {
+		plx = MIN_PARALLAX
+	}
+	r, v := p.spaceMotion(plx)
+	dt := (epoch - p.Epoch) / DAYS_PER_JULIAN_YEAR
+	q := r.Add(v.Scale(dt))
+	dist := q.Norm()
+	u := q.Scale(1. / dist)
+	east, north := localBasis(u)
+
+	coord, err := CoordinateOfSphere(p.Coord.System(), u.ToSpherical().Normalize())
+	if err != nil {
+		return nil, err
+	}
+	c := &SkyPosition{Coord: coord, Epoch: epoch}
+	c.PmX = v.Dot(east) / dist * RadToDeg(1.) * 3.6e6
+	c.PmY = v.Dot(north) / dist * RadToDeg(1.) * 3.6e6
+	c.RadialVelocity = p.RadialVelocity
+	if p.Parallax > 0 {
+		c.Parallax = PARSEC_AU / dist * 1e3
+		c.RadialVelocity = v.Dot(u) * AU_METER / 1e3 / (DAYS_PER_JULIAN_YEAR * SECONDS_PER_DAY)
+	}
+	return c, nil
+}
+
+// spaceMotion returns the position [AU] and the velocity [AU/yr] of the source in its frame.
+func (p *SkyPosition) spaceMotion(plx float64) (*Cartesian, *Cartesian) {
+	u := p.Coord.ToCartesian()
+	east, north := localBasis(u)
+	dist := PARSEC_AU / (plx * 1e-3)
+	masToRad := DegToRad(1. / 3.6e6)
+	rv := p.RadialVelocity * 1e3 / AU_METER * DAYS_PER_JULIAN_YEAR * SECONDS_PER_DAY
+	v := east.Scale(p.PmX * masToRad * dist).Add(north.Scale(p.PmY * masToRad * dist)).Add(u.Scale(rv))
+	return u.Scale(dist), v
+}
+
+// ConvertTo is like Convert, but exits the program on error.
+func (p *SkyPosition) ConvertTo(system string) *SkyPosition {
+	c, err := p.Convert(system)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return c
+}
+
+// Convert returns the position and the proper motion in another system at the same epoch.
+// Between FK4 and the other frames, the position and the space motion are transformed
+// together (Standish 1982, as SOFA fk425 and fk524) at the epochs B1950 and J2000.
+// Otherwise the proper motion is carried through the frame transformation by a central
+// difference, which is exact for rotations.
+func (p *SkyPosition) Convert(system string) (*SkyPosition, error) {
+	c, err := p.Coord.Convert(system)
+	if err != nil {
+		return nil, err
+	}
+	_, fromFK4 := fk4Equinox(p.Coord)
+	equinox, toFK4 := fk4Equinox(c)
+	if !fromFK4 && !toFK4 {
+		return p.carry(c)
+	}
+
+	// Through FK5 J2000
+	q := p
+	if fromFK4 {
+		if q, err = q.fk4ToFK5(); err != nil {
+			return nil, err
+		}
+	} else if q, err = q.Convert(SYSTEM_J2000); err != nil {
+		return nil, err
+	}
+	if !toFK4 {
+		if q, err = q.Convert(system); err != nil {
+			return nil, err
+		}
+		return q.Propagate(p.Epoch)
+	}
+	if q, err = q.fk5ToFK4(); err != nil {
+		return nil, err
+	}
+	if q, err = q.carryBy(system, fk4Precession(BesselianEpochToJD(1950.), equinox)); err != nil {
+		return nil, err
+	}
+	return q.Propagate(p.Epoch)
+}
+
+// carry returns the position c with the proper motion of p transformed into its frame.
+func (p *SkyPosition) carry(c Coordinate) (*SkyPosition, error) {
+	from, err := lookupFrameNode(p.Coord.System())
+	if err != nil {
+		return nil, err
+	}
+	to, err := lookupFrameNode(c.System())
+	if err != nil {
+		return nil, err
+	}
+	return p.carryTo(c, func(v *Cartesian) *Cartesian { return convertVector(v, from, to) }), nil
+}
+
+// carryBy returns p in system, of which f transforms the vectors from the frame of p.
+func (p *SkyPosition) carryBy(system string, f Transform) (*SkyPosition, error) {
+	c, err := CoordinateOfSphere(system, f(p.Coord.ToCartesian()).ToSpherical().Normalize())
+	if err != nil {
+		return nil, err
+	}
+	return p.carryTo(c, f), nil
+}
+
+func (p *SkyPosition) carryTo(c Coordinate, f Transform) *SkyPosition {
+	converted := &SkyPosition{Coord: c, Parallax: p.Parallax, RadialVelocity: p.RadialVelocity, Epoch: p.Epoch}
+	u := p.Coord.ToCartesian()
+	east, north := localBasis(u)
+	masToRad := DegToRad(1. / 3.6e6)
+	w := east.Scale(p.PmX * masToRad).Add(north.Scale(p.PmY * masToRad))
+	if norm := w.Norm(); norm > 0 {
+		const h = 1e-6 // rad
+		step := w.Scale(h / norm)
+		plus := f(u.Add(step).Unit())
+		minus := f(u.Sub(step).Unit())
+		dw := plus.Sub(minus).Scale(norm / (2. * h))
+		e, n := localBasis(c.ToCartesian())
+		converted.PmX = dw.Dot(e) / masToRad
+		converted.PmY = dw.Dot(n) / masToRad
+	}
+	return converted
+}
+
+// fk4ToFK5 converts p in any FK4 frame to FK5 J2000 at the epoch J2000.
+func (p *SkyPosition) fk4ToFK5() (*SkyPosition, error) {
+	b1950 := BesselianEpochToJD(1950.)
+	equinox, _ := fk4Equinox(p.Coord)
+	q, err := p.carryBy(SYSTEM_B1950, fk4Precession(equinox, b1950))
+	if err != nil {
+		return nil, err
+	}
+	if q, err = q.Propagate(b1950); err != nil {
+		return nil, err
+	}
+	pos, vel := q.posVel()
+	return q.fromPosVel(SYSTEM_J2000, JD_J2000)(fk4ToFK5PosVel(pos, vel))
+}
+
+// fk5ToFK4 converts p in FK5 J2000 to FK4 B1950 at the epoch B1950.
+func (p *SkyPosition) fk5ToFK4() (*SkyPosition, error) {
+	q, err := p.Propagate(JD_J2000)
+	if err != nil {
+		return nil, err
+	}
+	pos, vel := q.posVel()
+	return q.fromPosVel(SYSTEM_B1950, BesselianEpochToJD(1950.))(fk5ToFK4PosVel(pos, vel))
+}
+
+// posVel returns the unit position vector and its velocity in arcsec per century.
+func (p *SkyPosition) posVel() (*Cartesian, *Cartesian) {
+	u := p.Coord.ToCartesian()
+	east, north := localBasis(u)
+	rv := p.RadialVelocity * p.Parallax * 1e-3 * FK4_VELOCITY_FACTOR
+	return u, east.Scale(p.PmX * 0.1).Add(north.Scale(p.PmY * 0.1)).Add(u.Scale(rv))
+}
+
+// fromPosVel returns the inverse of posVel, which creates a position in system at epoch.
+func (p *SkyPosition) fromPosVel(system string, epoch float64) func(*Cartesian, *Cartesian) (*SkyPosition, error) {
+	return func(pos, vel *Cartesian) (*SkyPosition, error) {
+		r := pos.Norm()
+		u := pos.Scale(1. / r)
+		c, err := CoordinateOfSphere(system, u.ToSpherical().Normalize())
+		if err != nil {
+			return nil, err
+		}
+		east, north := localBasis(u)
+		q := &SkyPosition{Coord: c, Epoch: epoch, RadialVelocity: p.RadialVelocity}
+		q.PmX = vel.Dot(east) / r * 10.
+		q.PmY = vel.Dot(north) / r * 10.
+		if p.Parallax > 0 {
+			q.RadialVelocity = vel.Dot(u) / (p.Parallax * 1e-3 * FK4_VELOCITY_FACTOR)
+			q.Parallax = p.Parallax / r
+		}
+		return q, nil
+	}
+}
+
+// fk4Equinox returns the equinox (JD) of c if it is in one of the FK4 frames.
+func fk4Equinox(c Coordinate) (float64, bool) {
+	switch c := c.(type) {
+	case *B1950:
+		return BesselianEpochToJD(1950.), true
+	case *FK4:
+		return c.Equinox, true
+	}
+	return 0., false
+}
+
+// fk4Precession returns the transformation between the FK4 equinoxes from and to,
+// including the change of the E-terms.
+func fk4Precession(from, to float64) Transform {
+	if from == to {
+		return identity
+	}
+	m := PrecessionMatrixNewcomb(from, to)
+	efrom, eto := fk4EquinoxETerms(from), fk4EquinoxETerms(to)
+	return func(v *Cartesian) *Cartesian {
+		return addETerms(m.Apply(removeETerms(v, efrom)), eto)
+	}
+}
+
+// fk4EquinoxETerms returns the E-terms of the FK4 equinox jd, as used by the FK4 frames.
+func fk4EquinoxETerms(jd float64) *Cartesian {
+	if jd == BesselianEpochToJD(1950.) {
+		return FK4_ETERMS
+	}
+	return fk4ETerms(jd)
+}
+
+// localBasis returns the unit vectors toward the increasing longitude and latitude at u.
+func localBasis(u *Cartesian) (*Cartesian, *Cartesian) {
+	lon := math.Atan2(u.Y, u.X)
+	lat := math.Atan2(u.Z, math.Hypot(u.X, u.Y))
+	east := &Cartesian{X: -math.Sin(lon), Y: math.Cos(lon), Z: 0.}
+	north := &Cartesian{X: -math.Sin(lat) * math.Cos(lon), Y: -math.Sin(lat) * math.Sin(lon), Z: math.Cos(lat)}
+	return east, north
+}
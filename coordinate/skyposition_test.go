@@ -0,0 +1,72 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+const RAD_TO_MAS float64 = 180. / math.Pi * 3.6e6
+
+// SOFA iauFk524 test case, from FK5 J2000 to FK4 B1950 at the epoch B1950
+func TestSkyPositionFK524(t *testing.T) {
+	ra, dec := 0.8723503576487275595, -0.7517076365138887672
+	p := &SkyPosition{
+		Coord:          NewCoordinate(SYSTEM_J2000, RadToDeg(ra), RadToDeg(dec)),
+		PmX:            0.2019447755430472323e-4 * math.Cos(dec) * RAD_TO_MAS,
+		PmY:            0.3541563940505160433e-5 * RAD_TO_MAS,
+		Parallax:       155.9,
+		RadialVelocity: 86.87,
+		Epoch:          JD_J2000,
+	}
+	q, err := p.Convert(SYSTEM_B1950)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, err = q.Propagate(BesselianEpochToJD(1950.)); err != nil {
+		t.Fatal(err)
+	}
+	ra, dec = 0.8636359659799603487, -0.7550281733160843059
+	want := NewCoordinate(SYSTEM_B1950, RadToDeg(ra), RadToDeg(dec))
+	if sep := separationArcsec(t, q.Coord, want); sep > 1e-3 {
+		t.Errorf("position off by %g arcsec", sep)
+	}
+	if d := q.PmX - 0.2023628192747172486e-4*math.Cos(dec)*RAD_TO_MAS; math.Abs(d) > 0.1 {
+		t.Errorf("pmRA off by %g mas/yr", d)
+	}
+	if d := q.PmY - 0.3624459754935334718e-5*RAD_TO_MAS; math.Abs(d) > 0.1 {
+		t.Errorf("pmDec off by %g mas/yr", d)
+	}
+	if d := q.Parallax - 156.0079963299390241; math.Abs(d) > 0.01 {
+		t.Errorf("parallax off by %g mas", d)
+	}
+	if d := q.RadialVelocity - 86.79606353469163751; math.Abs(d) > 0.01 {
+		t.Errorf("radial velocity off by %g km/s", d)
+	}
+}
+
+func TestSkyPositionFK4RoundTrip(t *testing.T) {
+	p := &SkyPosition{
+		Coord:          NewCoordinate(SYSTEM_B1950, 83.633, 22.014),
+		PmX:            -50.,
+		PmY:            120.,
+		Parallax:       20.,
+		RadialVelocity: -30.,
+		Epoch:          BesselianEpochToJD(1950.),
+	}
+	q, err := p.Convert(SYSTEM_J2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, err = q.Convert(SYSTEM_B1950); err != nil {
+		t.Fatal(err)
+	}
+	if sep := separationArcsec(t, q.Coord, p.Coord); sep > 1e-4 {
+		t.Errorf("position off by %g arcsec", sep)
+	}
+	if math.Abs(q.PmX-p.PmX) > 1e-3 || math.Abs(q.PmY-p.PmY) > 1e-3 {
+		t.Errorf("proper motion (%g, %g) mas/yr", q.PmX, q.PmY)
+	}
+	if math.Abs(q.Parallax-p.Parallax) > 1e-4 || math.Abs(q.RadialVelocity-p.RadialVelocity) > 1e-4 {
+		t.Errorf("parallax %g mas, radial velocity %g km/s", q.Parallax, q.RadialVelocity)
+	}
+}
@@ -0,0 +1,241 @@
+package coordinate
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+const (
+	// Precisions of the Sun and the Moon
+	PRECISION_LOW    string = `low`    // Astronomical Almanac low-precision formulae: 0.01 deg (Sun), 0.3 deg (Moon)
+	PRECISION_MEDIUM string = `medium` // truncated VSOP87 and ELP (Meeus): 1 arcsec (Sun), 10 arcsec in longitude and 4 arcsec in latitude (Moon)
+	PRECISION_HIGH   string = `high`   // numerical ephemeris set with SetEphemeris
+
+	EARTH_RADIUS_KM float64 = 6378.137
+)
+
+/* Sun and Moon */
+// Sun returns the position of the Sun in the mean ecliptic and equinox of date at t,
+// seen from o, or from the geocenter if o is nil. Aberration is not included;
+// ToAltAz and ApparentPlace apply it.
+// precision is PRECISION_LOW, PRECISION_MEDIUM or PRECISION_HIGH.
+func Sun(o Observatory, t astrotime.Instant, precision string) (Coordinate, error) {
+	jd := t.JD(astrotime.TT)
+	var lon, lat, dist float64
+	switch precision {
+	case PRECISION_LOW:
+		lon, lat, dist = sunLow(jd)
+	case PRECISION_MEDIUM:
+		lon, lat, dist = sunMedium(jd)
+	case PRECISION_HIGH:
+		return ephemerisBody(NAIF_SUN, o, t)
+	default:
+		return nil, fmt.Errorf("unknown precision %q", precision)
+	}
+	return topocentric(o, t, lon, lat, dist*AU_METER)
+}
+
+// Moon returns the position of the Moon in the mean ecliptic and equinox of date at t,
+// seen from o, or from the geocenter if o is nil. Aberration is not included.
+// precision is PRECISION_LOW, PRECISION_MEDIUM or PRECISION_HIGH.
+func Moon(o Observatory, t astrotime.Instant, precision string) (Coordinate, error) {
+	jd := t.JD(astrotime.TT)
+	var lon, lat, dist float64
+	switch precision {
+	case PRECISION_LOW:
+		lon, lat, dist = moonLow(jd)
+	case PRECISION_MEDIUM:
+		lon, lat, dist = moonMedium(jd)
+	case PRECISION_HIGH:
+		return ephemerisBody(NAIF_MOON, o, t)
+	default:
+		return nil, fmt.Errorf("unknown precision %q", precision)
+	}
+	return topocentric(o, t, lon, lat, dist*1e3)
+}
+
+// ephemerisBody returns the light-time corrected position of target from the ephemeris
+// in the mean ecliptic and equinox of date.
+func ephemerisBody(target int, o Observatory, t astrotime.Instant) (Coordinate, error) {
+	e := currentEphemeris()
+	if e == nil {
+		return nil, ErrNoEphemeris
+	}
+	p, err := ephemerisPosition(e, target, o, t)
+	if err != nil {
+		return nil, err
+	}
+	return icrsVectorTo(p, EclipticOfDate(t.JD(astrotime.TT)))
+}
+
+// topocentric returns the direction of the body at the geocentric ecliptic longitude and latitude [deg]
+// of date and the distance [m], seen from o.
+func topocentric(o Observatory, t astrotime.Instant, lon, lat, dist float64) (Coordinate, error) {
+	system := EclipticOfDate(t.JD(astrotime.TT))
+	s := &Spherical{X: NewAngle(lon), Y: NewAngle(lat)}
+	if o == nil {
+		return CoordinateOfSphere(system, s.Normalize())
+	}
+	from, err := lookupFrameNode(SYSTEM_ICRS)
+	if err != nil {
+		return nil, err
+	}
+	to, err := lookupFrameNode(system)
+	if err != nil {
+		return nil, err
+	}
+	r, _ := observatoryGCRS(o, t)
+	v := s.ToCartesian().Scale(dist).Sub(convertVector(r, from, to))
+	return CoordinateOfSphere(system, v.ToSpherical().Normalize())
+}
+
+// sunLow returns the ecliptic longitude, latitude [deg] and the distance [AU] of the Sun
+// (Astronomical Almanac, section C), without the aberration of -20.5 arcsec included in the formula.
+func sunLow(jd float64) (float64, float64, float64) {
+	n := jd - JD_J2000
+	l := 280.460 + 0.9856474*n
+	g := DegToRad(357.528 + 0.9856003*n)
+	r := 1.00014 - 0.01671*math.Cos(g) - 0.00014*math.Cos(2.*g)
+	lon := l + 1.915*math.Sin(g) + 0.020*math.Sin(2.*g) + 20.4898/r/3600.
+	return normalizeDegree(lon), 0., r
+}
+
+// sunMedium returns the ecliptic longitude, latitude [deg] and the distance [AU] of the Sun
+// from VSOP87, reduced to the FK5 system (Meeus, chapter 25).
+func sunMedium(jd float64) (float64, float64, float64) {
+	l, b, r := earthHeliocentric(jd)
+	lon := RadToDeg(l) + 180.
+	lat := -RadToDeg(b)
+
+	t := julianCenturies(jd)
+	lp := DegToRad(lon - 1.397*t - 0.00031*t*t)
+	lon += (-0.09033 + 0.03916*(math.Cos(lp)+math.Sin(lp))*math.Tan(DegToRad(lat))) / 3600.
+	lat += 0.03916 * (math.Cos(lp) - math.Sin(lp)) / 3600.
+	return normalizeDegree(lon), lat, r
+}
+
+// moonLow returns the ecliptic longitude, latitude [deg] and the distance [km] of the Moon
+// (Astronomical Almanac, section D).
+func moonLow(jd float64) (float64, float64, float64) {
+	t := julianCenturies(jd)
+	sin := func(deg float64) float64 { return math.Sin(DegToRad(deg)) }
+	cos := func(deg float64) float64 { return math.Cos(DegToRad(deg)) }
+	lon := 218.32 + 481267.881*t +
+		6.29*sin(135.0+477198.87*t) - 1.27*sin(259.3-413335.36*t) +
+		0.66*sin(235.7+890534.22*t) + 0.21*sin(269.9+954397.74*t) -
+		0.19*sin(357.5+35999.05*t) - 0.11*sin(186.5+966404.03*t)
+	lat := 5.13*sin(93.3+483202.02*t) + 0.28*sin(228.2+960400.89*t) -
+		0.28*sin(318.3+6003.15*t) - 0.17*sin(217.6-407332.21*t)
+	parallax := 0.9508 + 0.0518*cos(135.0+477198.87*t) + 0.0095*cos(259.3-413335.36*t) +
+		0.0078*cos(235.7+890534.22*t) + 0.0028*cos(269.9+954397.74*t)
+	return normalizeDegree(lon), lat, EARTH_RADIUS_KM / sin(parallax)
+}
+
+// Periodic terms of the Moon (Meeus, tables 47.A and 47.B): multiples of D, M, M', F
+// and the amplitudes of the longitude [1e-6 deg], the distance [1e-3 km] or the latitude [1e-6 deg].
+type moonTerm struct {
+	d, m, mp, f float64
+	a, b        float64
+}
+
+var (
+	moonLonDistTerms = []moonTerm{
+		{0, 0, 1, 0, 6288774, -20905355}, {2, 0, -1, 0, 1274027, -3699111},
+		{2, 0, 0, 0, 658314, -2955968}, {0, 0, 2, 0, 213618, -569925},
+		{0, 1, 0, 0, -185116, 48888}, {0, 0, 0, 2, -114332, -3149},
+		{2, 0, -2, 0, 58793, 246158}, {2, -1, -1, 0, 57066, -152138},
+		{2, 0, 1, 0, 53322, -170733}, {2, -1, 0, 0, 45758, -204586},
+		{0, 1, -1, 0, -40923, -129620}, {1, 0, 0, 0, -34720, 108743},
+		{0, 1, 1, 0, -30383, 104755}, {2, 0, 0, -2, 15327, 10321},
+		{0, 0, 1, 2, -12528, 0}, {0, 0, 1, -2, 10980, 79661},
+		{4, 0, -1, 0, 10675, -34782}, {0, 0, 3, 0, 10034, -23210},
+		{4, 0, -2, 0, 8548, -21636}, {2, 1, -1, 0, -7888, 24208},
+		{2, 1, 0, 0, -6766, 30824}, {1, 0, -1, 0, -5163, -8379},
+		{1, 1, 0, 0, 4987, -16675}, {2, -1, 1, 0, 4036, -12831},
+		{2, 0, 2, 0, 3994, -10445}, {4, 0, 0, 0, 3861, -11650},
+		{2, 0, -3, 0, 3665, 14403}, {0, 1, -2, 0, -2689, -7003},
+		{2, 0, -1, 2, -2602, 0}, {2, -1, -2, 0, 2390, 10056},
+		{1, 0, 1, 0, -2348, 6322}, {2, -2, 0, 0, 2236, -9884},
+		{0, 1, 2, 0, -2120, 5751}, {0, 2, 0, 0, -2069, 0},
+		{2, -2, -1, 0, 2048, -4950}, {2, 0, 1, -2, -1773, 4130},
+		{2, 0, 0, 2, -1595, 0}, {4, -1, -1, 0, 1215, -3958},
+		{0, 0, 2, 2, -1110, 0}, {3, 0, -1, 0, -892, 3258},
+		{2, 1, 1, 0, -810, 2616}, {4, -1, -2, 0, 759, -1897},
+		{0, 2, -1, 0, -713, -2117}, {2, 2, -1, 0, -700, 2354},
+		{2, 1, -2, 0, 691, 0}, {2, -1, 0, -2, 596, 0},
+		{4, 0, 1, 0, 549, -1423}, {0, 0, 4, 0, 537, -1117},
+		{4, -1, 0, 0, 520, -1571}, {1, 0, -2, 0, -487, -1739},
+		{2, 1, 0, -2, -399, 0}, {0, 0, 2, -2, -381, -4421},
+		{1, 1, 1, 0, 351, 0}, {3, 0, -2, 0, -340, 0},
+		{4, 0, -3, 0, 330, 0}, {2, -1, 2, 0, 327, 0},
+		{0, 2, 1, 0, -323, 1165}, {1, 1, -1, 0, 299, 0},
+		{2, 0, 3, 0, 294, 0}, {2, 0, -1, -2, 0, 8752},
+	}
+
+	moonLatTerms = []moonTerm{
+		{0, 0, 0, 1, 5128122, 0}, {0, 0, 1, 1, 280602, 0},
+		{0, 0, 1, -1, 277693, 0}, {2, 0, 0, -1, 173237, 0},
+		{2, 0, -1, 1, 55413, 0}, {2, 0, -1, -1, 46271, 0},
+		{2, 0, 0, 1, 32573, 0}, {0, 0, 2, 1, 17198, 0},
+		{2, 0, 1, -1, 9266, 0}, {0, 0, 2, -1, 8822, 0},
+		{2, -1, 0, -1, 8216, 0}, {2, 0, -2, -1, 4324, 0},
+		{2, 0, 1, 1, 4200, 0}, {2, 1, 0, -1, -3359, 0},
+		{2, -1, -1, 1, 2463, 0}, {2, -1, 0, 1, 2211, 0},
+		{2, -1, -1, -1, 2065, 0}, {0, 1, -1, -1, -1870, 0},
+		{4, 0, -1, -1, 1828, 0}, {0, 1, 0, 1, -1794, 0},
+		{0, 0, 0, 3, -1749, 0}, {0, 1, -1, 1, -1565, 0},
+		{1, 0, 0, 1, -1491, 0}, {0, 1, 1, 1, -1475, 0},
+		{0, 1, 1, -1, -1410, 0}, {0, 1, 0, -1, -1344, 0},
+		{1, 0, 0, -1, -1335, 0}, {0, 0, 3, 1, 1107, 0},
+		{4, 0, 0, -1, 1021, 0}, {4, 0, -1, 1, 833, 0},
+		{0, 0, 1, -3, 777, 0}, {4, 0, -2, 1, 671, 0},
+		{2, 0, 0, -3, 607, 0}, {2, 0, 2, -1, 596, 0},
+		{2, -1, 1, -1, 491, 0}, {2, 0, -2, 1, -451, 0},
+		{0, 0, 3, -1, 439, 0}, {2, 0, 2, 1, 422, 0},
+		{2, 0, -3, -1, 421, 0}, {2, 1, -1, 1, -366, 0},
+		{2, 1, 0, 1, -351, 0}, {4, 0, 0, 1, 331, 0},
+		{2, -1, 1, 1, 315, 0}, {2, -2, 0, -1, 302, 0},
+		{0, 0, 1, 3, -283, 0}, {2, 1, 1, -1, -229, 0},
+		{1, 1, 0, -1, 223, 0}, {1, 1, 0, 1, 223, 0},
+		{0, 1, -2, -1, -220, 0}, {2, 1, -1, -1, -220, 0},
+		{1, 0, 1, 1, -185, 0}, {2, -1, -2, -1, 181, 0},
+		{0, 1, 2, 1, -177, 0}, {4, 0, -2, -1, 176, 0},
+		{4, -1, -1, -1, 166, 0}, {1, 0, 1, -1, -164, 0},
+		{4, 0, 1, -1, 132, 0}, {1, 0, -1, -1, -119, 0},
+		{4, -1, 0, -1, 115, 0}, {2, -2, 0, 1, 107, 0},
+	}
+)
+
+// moonMedium returns the ecliptic longitude, latitude [deg] and the distance [km] of the Moon
+// from the terms of ELP-2000/82 truncated by Meeus (chapter 47).
+func moonMedium(jd float64) (float64, float64, float64) {
+	t := julianCenturies(jd)
+	t2, t3, t4 := t*t, t*t*t, t*t*t*t
+	lp := DegToRad(218.3164477 + 481267.88123421*t - 0.0015786*t2 + t3/538841. - t4/65194000.)
+	d := DegToRad(297.8501921 + 445267.1114034*t - 0.0018819*t2 + t3/545868. - t4/113065000.)
+	m := DegToRad(357.5291092 + 35999.0502909*t - 0.0001536*t2 + t3/24490000.)
+	mp := DegToRad(134.9633964 + 477198.8675055*t + 0.0087414*t2 + t3/69699. - t4/14712000.)
+	f := DegToRad(93.2720950 + 483202.0175233*t - 0.0036539*t2 - t3/3526000. + t4/863310000.)
+	a1 := DegToRad(119.75 + 131.849*t)
+	a2 := DegToRad(53.09 + 479264.290*t)
+	a3 := DegToRad(313.45 + 481266.484*t)
+	e := 1. - 0.002516*t - 0.0000074*t2
+
+	var sl, sr, sb float64
+	for _, term := range moonLonDistTerms {
+		arg := term.d*d + term.m*m + term.mp*mp + term.f*f
+		w := math.Pow(e, math.Abs(term.m))
+		sl += w * term.a * math.Sin(arg)
+		sr += w * term.b * math.Cos(arg)
+	}
+	for _, term := range moonLatTerms {
+		arg := term.d*d + term.m*m + term.mp*mp + term.f*f
+		sb += math.Pow(e, math.Abs(term.m)) * term.a * math.Sin(arg)
+	}
+	sl += 3958.*math.Sin(a1) + 1962.*math.Sin(lp-f) + 318.*math.Sin(a2)
+	sb += -2235.*math.Sin(lp) + 382.*math.Sin(a3) + 175.*math.Sin(a1-f) + 175.*math.Sin(a1+f) +
+		127.*math.Sin(lp-mp) - 115.*math.Sin(lp+mp)
+	return normalizeDegree(RadToDeg(lp) + sl/1e6), sb / 1e6, 385000.56 + sr/1e3
+}
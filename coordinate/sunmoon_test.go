@@ -0,0 +1,34 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+)
+
+// Meeus, Astronomical Algorithms, example 25.b (1992 October 13.0 TD)
+func TestSunMedium(t *testing.T) {
+	lon, lat, r := sunMedium(2448908.5)
+	if math.Abs(lon-199.907347) > 1e-5 {
+		t.Errorf("lon = %.6f", lon)
+	}
+	if math.Abs(lat-0.62/3600.) > 1e-5 {
+		t.Errorf("lat = %.6f", lat)
+	}
+	if math.Abs(r-0.99760775) > 1e-8 {
+		t.Errorf("r = %.8f", r)
+	}
+}
+
+// Meeus, Astronomical Algorithms, example 47.a (1992 April 12.0 TD)
+func TestMoonMedium(t *testing.T) {
+	lon, lat, dist := moonMedium(2448724.5)
+	if math.Abs(lon-133.162655) > 1e-6 {
+		t.Errorf("lon = %.6f", lon)
+	}
+	if math.Abs(lat-(-3.229126)) > 1e-6 {
+		t.Errorf("lat = %.6f", lat)
+	}
+	if math.Abs(dist-368409.7) > 0.1 {
+		t.Errorf("dist = %.1f", dist)
+	}
+}
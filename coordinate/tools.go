@@ -1,7 +1,10 @@
 package coordinate
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 func DegToRad(deg float64) float64 {
@@ -26,3 +29,86 @@ func Elevation(lst *Angle, c Coordinate, o Observatory) float64 {
 
 	return 90. - 180./math.Pi*math.Acos(A+B*math.Cos(x-ra))
 }
+
+// Azimuth returns the azimuth [deg] of c from o at lst, measured from north through east.
+func Azimuth(lst *Angle, c Coordinate, o Observatory) float64 {
+	az, _ := equatorialToHorizontal(lst.Radian()-c.GetX().Radian(), c.GetY().Radian(), o.Latitude().Radian())
+	return RadToDeg(az)
+}
+
+const (
+	JD_J2000 float64 = 2451545.0
+	JD_B1900 float64 = 2415020.31352
+
+	DAYS_PER_JULIAN_YEAR    float64 = 365.25
+	DAYS_PER_BESSELIAN_YEAR float64 = 365.242198781
+	DAYS_PER_JULIAN_CENTURY float64 = 36525.
+)
+
+func ArcsecToRad(arcsec float64) float64 {
+	return arcsec * math.Pi / 180. / 3600.
+}
+
+/* Epochs */
+// Epochs are handled as Julian dates (TT). ParseEpoch accepts Julian ("J2000.0"),
+// Besselian ("B1950") epochs, Julian dates ("JD2451545.0") and plain numbers
+// (taken as Julian epochs).
+func JulianEpochToJD(epoch float64) float64 {
+	return JD_J2000 + (epoch-2000.)*DAYS_PER_JULIAN_YEAR
+}
+
+func JDToJulianEpoch(jd float64) float64 {
+	return 2000. + (jd-JD_J2000)/DAYS_PER_JULIAN_YEAR
+}
+
+func BesselianEpochToJD(epoch float64) float64 {
+	return JD_B1900 + (epoch-1900.)*DAYS_PER_BESSELIAN_YEAR
+}
+
+func JDToBesselianEpoch(jd float64) float64 {
+	return 1900. + (jd-JD_B1900)/DAYS_PER_BESSELIAN_YEAR
+}
+
+func ParseEpoch(epoch string) (float64, error) {
+	s := strings.ToUpper(strings.TrimSpace(epoch))
+	convert := JulianEpochToJD
+	switch {
+	case strings.HasPrefix(s, `JD`):
+		s = s[2:]
+		convert = func(jd float64) float64 { return jd }
+	case strings.HasPrefix(s, `J`):
+		s = s[1:]
+	case strings.HasPrefix(s, `B`):
+		s = s[1:]
+		convert = BesselianEpochToJD
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0., fmt.Errorf("invalid epoch %q", epoch)
+	}
+	return convert(v), nil
+}
+
+// FormatJulianEpoch returns jd as a Julian epoch string, e.g. "J2020.5".
+func FormatJulianEpoch(jd float64) string {
+	return `J` + strconv.FormatFloat(math.Round(JDToJulianEpoch(jd)*1e9)/1e9, 'f', -1, 64)
+}
+
+// FormatBesselianEpoch returns jd as a Besselian epoch string, e.g. "B1950".
+func FormatBesselianEpoch(jd float64) string {
+	return `B` + strconv.FormatFloat(math.Round(JDToBesselianEpoch(jd)*1e9)/1e9, 'f', -1, 64)
+}
+
+// normalizeDegree returns deg wrapped into [0, 360).
+func normalizeDegree(deg float64) float64 {
+	deg = math.Mod(deg, 360.)
+	if deg < 0 {
+		deg += 360.
+	}
+	return deg
+}
+
+// formatJD returns jd as an epoch string keeping full precision, e.g. "JD2460000.5".
+func formatJD(jd float64) string {
+	return `JD` + strconv.FormatFloat(jd, 'f', -1, 64)
+}
@@ -0,0 +1,153 @@
+package coordinate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+const (
+	/* Geometric elevation [deg] of the center of the Sun */
+	SUNRISE_ELEVATION     float64 = -0.833 // upper limb on the horizon with the standard refraction
+	TWILIGHT_CIVIL        float64 = -6.
+	TWILIGHT_NAUTICAL     float64 = -12.
+	TWILIGHT_ASTRONOMICAL float64 = -18.
+)
+
+const (
+	// Longest time searched for the end of a dark interval
+	TWILIGHT_SEARCH_WINDOW time.Duration = 48 * time.Hour
+)
+
+/* Interval */
+// Interval is the period from Start to End.
+type Interval struct {
+	Start astrotime.Instant
+	End   astrotime.Instant
+}
+
+func (i *Interval) String() string {
+	return fmt.Sprintf(`%s - %s`, i.Start, i.End)
+}
+
+func (i *Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+func (i *Interval) Contains(t astrotime.Instant) bool {
+	return !t.Before(i.Start) && !t.After(i.End)
+}
+
+// Local returns Start and End in the time zone of o.
+func (i *Interval) Local(o Observatory) (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation(o.Timezone())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return i.Start.Time().In(loc), i.End.Time().In(loc), nil
+}
+
+/* Twilight */
+// Night holds the periods of a night, from the evening to the morning,
+// when the Sun is below the horizon and below each twilight.
+// A period is nil if the Sun does not go down enough, e.g. in a polar summer.
+type Night struct {
+	Night        *Interval // sunset to sunrise
+	Civil        *Interval // end of the evening civil twilight to the start of the morning one
+	Nautical     *Interval
+	Astronomical *Interval // the dark time
+}
+
+// LocalNoon returns the noon of the date in the time zone of o,
+// which is the date expected by Twilights and DarkInterval.
+func LocalNoon(o Observatory, year int, month time.Month, day int) (astrotime.Instant, error) {
+	loc, err := time.LoadLocation(o.Timezone())
+	if err != nil {
+		return astrotime.Instant{}, err
+	}
+	return astrotime.FromTime(time.Date(year, month, day, 12, 0, 0, 0, loc)), nil
+}
+
+// Twilights returns the night beginning in the 24 hours from date at o.
+func Twilights(o Observatory, date astrotime.Instant) (*Night, error) {
+	n := &Night{}
+	for _, p := range []struct {
+		interval **Interval
+		limit    float64
+	}{
+		{&n.Night, SUNRISE_ELEVATION},
+		{&n.Civil, TWILIGHT_CIVIL},
+		{&n.Nautical, TWILIGHT_NAUTICAL},
+		{&n.Astronomical, TWILIGHT_ASTRONOMICAL},
+	} {
+		i, err := DarkInterval(o, date, p.limit)
+		if err != nil {
+			return nil, err
+		}
+		*p.interval = i
+	}
+	return n, nil
+}
+
+// DarkInterval returns the period when the geometric elevation of the Sun is below limit [deg],
+// beginning in the 24 hours from date. If the Sun is already below limit at date, the period
+// begins at date, and it ends at most TWILIGHT_SEARCH_WINDOW after date.
+// It returns nil if the Sun stays above limit.
+func DarkInterval(o Observatory, date astrotime.Instant, limit float64) (*Interval, error) {
+	elevation := func(t astrotime.Instant) (float64, error) {
+		sun, err := Sun(o, t, PRECISION_MEDIUM)
+		if err != nil {
+			return 0., err
+		}
+		h, err := ToAltAz(sun, o, t)
+		if err != nil {
+			return 0., err
+		}
+		return h.El.Degree(), nil
+	}
+
+	start, err := nextCrossing(elevation, date, date.Add(24*time.Hour), limit, false)
+	if err != nil || start == nil {
+		return nil, err
+	}
+	end, err := nextCrossing(elevation, *start, date.Add(TWILIGHT_SEARCH_WINDOW), limit, true)
+	if err != nil {
+		return nil, err
+	}
+	if end == nil {
+		t := date.Add(TWILIGHT_SEARCH_WINDOW)
+		end = &t
+	}
+	return &Interval{Start: *start, End: *end}, nil
+}
+
+// nextCrossing returns the first time between from and until when the elevation
+// goes above (rising) or below limit. It returns from if the elevation is already
+// on that side, and nil if it never gets there.
+func nextCrossing(elevation func(astrotime.Instant) (float64, error), from, until astrotime.Instant, limit float64, rising bool) (*astrotime.Instant, error) {
+	reached := func(el float64) bool { return (el >= limit) == rising }
+	e, err := elevation(from)
+	if err != nil {
+		return nil, err
+	}
+	if reached(e) {
+		return &from, nil
+	}
+	for t := from; t.Before(until); {
+		next := t.Add(RISESET_STEP)
+		if next.After(until) {
+			next = until
+		}
+		e, err := elevation(next)
+		if err != nil {
+			return nil, err
+		}
+		if reached(e) {
+			c, err := findCrossing(elevation, t, next, limit)
+			return &c, err
+		}
+		t = next
+	}
+	return nil, nil
+}
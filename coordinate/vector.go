@@ -0,0 +1,39 @@
+package coordinate
+
+import (
+	"math"
+)
+
+/* Vector operations on Cartesian */
+func (c *Cartesian) Add(d *Cartesian) *Cartesian {
+	return &Cartesian{X: c.X + d.X, Y: c.Y + d.Y, Z: c.Z + d.Z}
+}
+
+func (c *Cartesian) Sub(d *Cartesian) *Cartesian {
+	return &Cartesian{X: c.X - d.X, Y: c.Y - d.Y, Z: c.Z - d.Z}
+}
+
+func (c *Cartesian) Scale(f float64) *Cartesian {
+	return &Cartesian{X: f * c.X, Y: f * c.Y, Z: f * c.Z}
+}
+
+func (c *Cartesian) Dot(d *Cartesian) float64 {
+	return c.X*d.X + c.Y*d.Y + c.Z*d.Z
+}
+
+func (c *Cartesian) Cross(d *Cartesian) *Cartesian {
+	return &Cartesian{
+		X: c.Y*d.Z - c.Z*d.Y,
+		Y: c.Z*d.X - c.X*d.Z,
+		Z: c.X*d.Y - c.Y*d.X,
+	}
+}
+
+func (c *Cartesian) Norm() float64 {
+	return math.Sqrt(c.Dot(c))
+}
+
+// Unit returns the vector scaled to unit length.
+func (c *Cartesian) Unit() *Cartesian {
+	return c.Scale(1. / c.Norm())
+}
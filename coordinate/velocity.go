@@ -0,0 +1,98 @@
+package coordinate
+
+import (
+	"fmt"
+
+	"github.com/yurutaso/astro/astrotime"
+	"github.com/yurutaso/astro/unit"
+)
+
+const (
+	/* Rest frames of radial velocities */
+	RESTFRAME_TOPOCENTRIC    string = `TOPO`
+	RESTFRAME_GEOCENTRIC     string = `GEO`
+	RESTFRAME_BARYCENTRIC    string = `BARY`
+	RESTFRAME_LSRK           string = `LSRK` // kinematic LSR: 20 km/s toward RA 18h, Dec +30d (B1900)
+	RESTFRAME_LSRD           string = `LSRD` // dynamical LSR: (U, V, W) = (9, 12, 7) km/s
+	RESTFRAME_GALACTOCENTRIC string = `GALACTO`
+
+	GALACTIC_ROTATION float64 = 220. // km/s of the LSR toward l = 90 deg (IAU 1985)
+)
+
+var (
+	// Velocities [km/s] of the Sun relative to the LSRs.
+	// The apex of the LSRK is precessed to J2000, which is taken to be ICRS.
+	SOLAR_MOTION_LSRK = (&Spherical{X: NewAngleFromHMS(18, 3, 50.29), Y: NewAngleFromDMS(30, 0, 16.8)}).ToCartesian().Scale(20.)
+	SOLAR_MOTION_LSRD = &Cartesian{X: 9., Y: 12., Z: 7.} // Galactic
+)
+
+/* Radial velocity corrections */
+// VelocityCorrection returns the velocity to be added to the topocentric radial velocity
+// toward c, observed from o at t, to obtain the radial velocity in frame (one of RESTFRAME_*).
+// The velocity is in m/s, and is positive when the observer approaches the source in frame.
+// The observer is at the geocenter if o is nil.
+func VelocityCorrection(c Coordinate, o Observatory, t astrotime.Instant, frame string) (unit.UnitValue, error) {
+	v, err := velocityCorrection(c, o, t, frame)
+	if err != nil {
+		return nil, err
+	}
+	return unit.NewUnitValue(v, unit.Meter(1.), unit.Second(-1.)), nil
+}
+
+// ConvertVelocity returns the radial velocity v toward c in frame from, converted into frame to.
+func ConvertVelocity(v unit.UnitValue, c Coordinate, o Observatory, t astrotime.Instant, from, to string) (unit.UnitValue, error) {
+	ms := unit.NewUnitValue(1., unit.Meter(1.), unit.Second(-1.)).Units()
+	si, err := v.As(ms)
+	if err != nil {
+		return nil, err
+	}
+	vfrom, err := velocityCorrection(c, o, t, from)
+	if err != nil {
+		return nil, err
+	}
+	vto, err := velocityCorrection(c, o, t, to)
+	if err != nil {
+		return nil, err
+	}
+	return unit.NewUnitValue(si.Value()+vto-vfrom, ms).As(v.Units())
+}
+
+// velocityCorrection returns the velocity [m/s] of the observer along the direction of c
+// relative to frame.
+func velocityCorrection(c Coordinate, o Observatory, t astrotime.Instant, frame string) (float64, error) {
+	icrs, err := c.Convert(SYSTEM_ICRS)
+	if err != nil {
+		return 0., err
+	}
+	n := icrs.ToCartesian()
+	toICRS := BIAS_MATRIX.Transpose().Multiply(GAL_MATRIX.Transpose())
+
+	var v float64
+	switch frame {
+	case RESTFRAME_GALACTOCENTRIC:
+		v += toICRS.Apply(&Cartesian{X: 0., Y: GALACTIC_ROTATION, Z: 0.}).Dot(n) * 1e3
+		fallthrough
+	case RESTFRAME_LSRD:
+		v += toICRS.Apply(SOLAR_MOTION_LSRD).Dot(n) * 1e3
+		fallthrough
+	case RESTFRAME_BARYCENTRIC:
+		vel := earthBarycentricVelocity(t.JD(astrotime.TDB))
+		v += vel.Dot(n) * AU_METER / SECONDS_PER_DAY
+		fallthrough
+	case RESTFRAME_GEOCENTRIC:
+		if o != nil {
+			_, vel := observatoryGCRS(o, t)
+			v += vel.Dot(n)
+		}
+		fallthrough
+	case RESTFRAME_TOPOCENTRIC:
+		return v, nil
+	case RESTFRAME_LSRK:
+		bary, err := velocityCorrection(c, o, t, RESTFRAME_BARYCENTRIC)
+		if err != nil {
+			return 0., err
+		}
+		return bary + SOLAR_MOTION_LSRK.Dot(n)*1e3, nil
+	}
+	return 0., fmt.Errorf("unknown rest frame %q", frame)
+}
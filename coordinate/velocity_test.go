@@ -0,0 +1,106 @@
+package coordinate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yurutaso/astro/astrotime"
+	"github.com/yurutaso/astro/unit"
+)
+
+func TestVelocityCorrectionLSR(t *testing.T) {
+	o := NRO()
+	ti := astrotime.FromTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	cases := []struct {
+		c     Coordinate
+		frame string
+		v     float64
+	}{
+		// toward the apexes of the solar motion
+		{NewCoordinateFromAngles(SYSTEM_ICRS, NewAngleFromHMS(18, 3, 50.29), NewAngleFromDMS(30, 0, 16.8)), RESTFRAME_LSRK, 20e3},
+		{NewCoordinate(SYSTEM_GAL, 90., 0.), RESTFRAME_LSRD, 12e3},
+		{NewCoordinate(SYSTEM_GAL, 0., 90.), RESTFRAME_LSRD, 7e3},
+		{NewCoordinate(SYSTEM_GAL, 90., 0.), RESTFRAME_GALACTOCENTRIC, 232e3},
+	}
+	for _, c := range cases {
+		bary, err := velocityCorrection(c.c, o, ti, RESTFRAME_BARYCENTRIC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := velocityCorrection(c.c, o, ti, c.frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(v-bary-c.v) > 1e-3 {
+			t.Errorf("%s: %g m/s", c.frame, v-bary)
+		}
+	}
+}
+
+func TestVelocityCorrectionBarycentric(t *testing.T) {
+	// The orbital velocity of the Earth is at most 30.3 km/s, and the rotation adds up to 0.5 km/s.
+	// The apex is 90 deg west of the Sun on the ecliptic.
+	ti := astrotime.FromTime(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC))
+	apex := NewCoordinate(EclipticOfDate(ti.JD(astrotime.TT)), 270., 0.)
+	v, err := velocityCorrection(apex, NRO(), ti, RESTFRAME_BARYCENTRIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v < 29.3e3 || v > 30.8e3 {
+		t.Errorf("toward the apex: %g m/s", v)
+	}
+}
+
+func TestConvertVelocity(t *testing.T) {
+	c := NewCoordinate(SYSTEM_J2000, 83.633, 22.014)
+	o := NRO()
+	ti := astrotime.FromTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	v := unit.NewUnitValue(10., unit.KiloMeter(1.), unit.Second(-1.))
+	lsr, err := ConvertVelocity(v, c, o, ti, RESTFRAME_TOPOCENTRIC, RESTFRAME_LSRK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dv, err := VelocityCorrection(c, o, ti, RESTFRAME_LSRK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := lsr.Value() - (10. + dv.Value()*1e-3); math.Abs(d) > 1e-9 {
+		t.Errorf("off by %g km/s", d)
+	}
+	back, err := ConvertVelocity(lsr, c, o, ti, RESTFRAME_LSRK, RESTFRAME_TOPOCENTRIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(back.Value()-10.) > 1e-9 {
+		t.Errorf("round trip = %g km/s", back.Value())
+	}
+}
+
+func TestVelocityCorrectionGeocenter(t *testing.T) {
+	// Without an observatory, the observer is at the geocenter and has no diurnal motion.
+	c := NewCoordinate(SYSTEM_J2000, 83.633, 22.014)
+	ti := astrotime.FromTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	geo, err := velocityCorrection(c, nil, ti, RESTFRAME_GEOCENTRIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if geo != 0. {
+		t.Errorf("geocentric correction at the geocenter = %g m/s", geo)
+	}
+	diurnal, err := velocityCorrection(c, NRO(), ti, RESTFRAME_GEOCENTRIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bary, err := velocityCorrection(c, NRO(), ti, RESTFRAME_BARYCENTRIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := velocityCorrection(c, nil, ti, RESTFRAME_BARYCENTRIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(v-(bary-diurnal)) > 1e-9 {
+		t.Errorf("barycentric correction at the geocenter = %g m/s, want %g", v, bary-diurnal)
+	}
+}
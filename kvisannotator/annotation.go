@@ -29,7 +29,13 @@ func (af *AnnotationFile) WriteTo(filename string) error {
 	defer file.Close()
 
 	for _, ann := range af.Annotations {
-		file.Write([]byte(ann.String()))
+		s, err := ann.Format()
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write([]byte(s)); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -63,8 +69,11 @@ func (opt *AnnotationOpt) String() string {
 }
 
 // Annotation
+// Format returns the annotation line(s) or an error if a coordinate cannot be converted.
+// String is the same as Format, but returns an empty string on error.
 type Annotation interface {
 	String() string
+	Format() (string, error)
 }
 
 /* Annotation Line */
@@ -79,9 +88,20 @@ func NewLine(from, to coordinate.Coordinate) *Line {
 }
 
 func (ann Line) String() string {
-	from := ann.From.ConvertTo(`J2000`)
-	to := ann.To.ConvertTo(`J2000`)
-	return fmt.Sprintf("%sLINE W %f %f W %f %f\n", ann.Option, from.GetX().Degree(), from.GetY().Degree(), to.GetX().Degree(), to.GetY().Degree())
+	s, _ := ann.Format()
+	return s
+}
+
+func (ann Line) Format() (string, error) {
+	from, err := ann.From.Convert(`J2000`)
+	if err != nil {
+		return ``, err
+	}
+	to, err := ann.To.Convert(`J2000`)
+	if err != nil {
+		return ``, err
+	}
+	return fmt.Sprintf("%sLINE W %f %f W %f %f\n", ann.Option, from.GetX().Degree(), from.GetY().Degree(), to.GetX().Degree(), to.GetY().Degree()), nil
 }
 
 /* Annotation Circle */
@@ -96,8 +116,16 @@ func NewCircle(center coordinate.Coordinate, width float64) *Circle {
 }
 
 func (ann Circle) String() string {
-	center := ann.Center.ConvertTo(`J2000`)
-	return fmt.Sprintf("%sCIRCLE W %f %f %f\n", ann.Option, center.GetX().Degree(), center.GetY().Degree(), ann.Width)
+	s, _ := ann.Format()
+	return s
+}
+
+func (ann Circle) Format() (string, error) {
+	center, err := ann.Center.Convert(`J2000`)
+	if err != nil {
+		return ``, err
+	}
+	return fmt.Sprintf("%sCIRCLE W %f %f %f\n", ann.Option, center.GetX().Degree(), center.GetY().Degree(), ann.Width), nil
 }
 
 /* Annotation Point */
@@ -111,8 +139,16 @@ func NewDot(center coordinate.Coordinate) *Dot {
 }
 
 func (ann Dot) String() string {
-	center := ann.Center.ConvertTo(`J2000`)
-	return fmt.Sprintf("%sDot W %f %f\n", ann.Option, center.GetX().Degree(), center.GetY().Degree())
+	s, _ := ann.Format()
+	return s
+}
+
+func (ann Dot) Format() (string, error) {
+	center, err := ann.Center.Convert(`J2000`)
+	if err != nil {
+		return ``, err
+	}
+	return fmt.Sprintf("%sDot W %f %f\n", ann.Option, center.GetX().Degree(), center.GetY().Degree()), nil
 }
 
 /* Annotation Text */
@@ -127,6 +163,14 @@ func NewText(left coordinate.Coordinate, text string) *Text {
 }
 
 func (ann Text) String() string {
-	left := ann.Left.ConvertTo(`J2000`)
-	return fmt.Sprintf("%sText W %f %f %s\n", ann.Option, left.GetX().Degree(), left.GetY().Degree(), ann.Text)
+	s, _ := ann.Format()
+	return s
+}
+
+func (ann Text) Format() (string, error) {
+	left, err := ann.Left.Convert(`J2000`)
+	if err != nil {
+		return ``, err
+	}
+	return fmt.Sprintf("%sText W %f %f %s\n", ann.Option, left.GetX().Degree(), left.GetY().Degree(), ann.Text), nil
 }
@@ -0,0 +1,108 @@
+package planner
+
+import (
+	"github.com/yurutaso/astro/astrotime"
+	"github.com/yurutaso/astro/coordinate"
+)
+
+/* Constraints */
+// Sample is the state of a target at a time step, which is checked by the constraints.
+// Elevation is the observed elevation of the target in degree, Azimuth is its azimuth and Horizon
+// is the elevation of the horizon mask of the observatory toward it, SunElevation is the geometric
+// elevation of the Sun, and the coordinates are in the true equator and equinox of date.
+type Sample struct {
+	Time         astrotime.Instant
+	LST          *coordinate.Angle
+	Target       *Target
+	Coord        coordinate.Coordinate
+	Elevation    float64
+	Azimuth      float64
+	Horizon      float64
+	Sun          coordinate.Coordinate
+	SunElevation float64
+	Moon         coordinate.Coordinate
+}
+
+// Constraint decides whether a target is observable at a sample.
+type Constraint interface {
+	Satisfied(*Sample) bool
+}
+
+// ElevationConstraint limits the observed elevation [deg].
+type ElevationConstraint struct {
+	Min float64
+	Max float64
+}
+
+// HorizonConstraint keeps the target at least Margin [deg] above the horizon mask of the observatory.
+type HorizonConstraint struct {
+	Margin float64
+}
+
+// AirmassConstraint limits the airmass. Model is one of the coordinate.AIRMASS_* models,
+// or AIRMASS_SECANT if empty.
+type AirmassConstraint struct {
+	Max   float64
+	Model string
+}
+
+// SunSeparationConstraint keeps the target at least Min [deg] away from the Sun.
+type SunSeparationConstraint struct {
+	Min float64
+}
+
+// MoonSeparationConstraint keeps the target at least Min [deg] away from the Moon.
+type MoonSeparationConstraint struct {
+	Min float64
+}
+
+// SunElevationConstraint limits the elevation of the Sun [deg], e.g. TWILIGHT_ASTRONOMICAL for dark time.
+type SunElevationConstraint struct {
+	Max float64
+}
+
+// LSTConstraint limits the local sidereal time from Start to End in hours.
+// The window may wrap around 0h, e.g. from 22h to 2h.
+type LSTConstraint struct {
+	Start float64
+	End   float64
+}
+
+func (c *ElevationConstraint) Satisfied(s *Sample) bool {
+	return c.Min <= s.Elevation && s.Elevation <= c.Max
+}
+
+func (c *HorizonConstraint) Satisfied(s *Sample) bool {
+	return s.Elevation >= s.Horizon+c.Margin
+}
+
+func (c *AirmassConstraint) Satisfied(s *Sample) bool {
+	model := c.Model
+	if model == `` {
+		model = coordinate.AIRMASS_SECANT
+	}
+	x, err := coordinate.Airmass(s.Elevation, model)
+	return err == nil && x <= c.Max
+}
+
+func (c *SunSeparationConstraint) Satisfied(s *Sample) bool {
+	sep, err := coordinate.Separation(s.Coord, s.Sun)
+	return err == nil && sep.Degree() >= c.Min
+}
+
+func (c *MoonSeparationConstraint) Satisfied(s *Sample) bool {
+	sep, err := coordinate.Separation(s.Coord, s.Moon)
+	return err == nil && sep.Degree() >= c.Min
+}
+
+func (c *SunElevationConstraint) Satisfied(s *Sample) bool {
+	return s.SunElevation <= c.Max
+}
+
+func (c *LSTConstraint) Satisfied(s *Sample) bool {
+	lst := s.LST.Hour()
+	if c.Start <= c.End {
+		return c.Start <= lst && lst <= c.End
+	}
+	return lst >= c.Start || lst <= c.End
+}
@@ -0,0 +1,235 @@
+package planner
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yurutaso/astro/astrotime"
+	"github.com/yurutaso/astro/coordinate"
+)
+
+const (
+	DEFAULT_STEP time.Duration = 5 * time.Minute
+)
+
+/* Planner */
+// Planner finds when targets satisfy all the constraints at an observatory.
+// The time range is sampled every Step, so the windows are accurate to Step.
+// The elevations of the targets include Refraction, or the refraction model of
+// the observatory if it is nil.
+type Planner struct {
+	Observatory coordinate.Observatory
+	Constraints []Constraint
+	Step        time.Duration
+	Refraction  coordinate.RefractionModel
+}
+
+func NewPlanner(o coordinate.Observatory, constraints ...Constraint) *Planner {
+	return &Planner{Observatory: o, Constraints: constraints, Step: DEFAULT_STEP}
+}
+
+// Visibility holds the windows when the target is observable, and the highest
+// observed elevation of the target in the windows.
+type Visibility struct {
+	Target           Target
+	Windows          []*coordinate.Interval
+	MaxElevation     float64
+	MaxElevationTime astrotime.Instant
+}
+
+// Total returns the total length of the windows.
+func (v *Visibility) Total() time.Duration {
+	var total time.Duration
+	for _, w := range v.Windows {
+		total += w.Duration()
+	}
+	return total
+}
+
+func (v *Visibility) Observable() bool {
+	return len(v.Windows) > 0
+}
+
+// step is the state of the sky shared by all the targets at a time step.
+type step struct {
+	time         astrotime.Instant
+	lst          *coordinate.Angle
+	sun          coordinate.Coordinate
+	sunElevation float64
+	moon         coordinate.Coordinate
+}
+
+// Plan returns the visibility of each target between start and end.
+// Positions are taken in the true equator and equinox at the middle of the range,
+// without aberration, which is accurate enough for planning.
+func (p *Planner) Plan(targets []Target, start, end astrotime.Instant) (Summary, error) {
+	if p.Step <= 0 {
+		return nil, fmt.Errorf("invalid step %s", p.Step)
+	}
+	mid := start.Add(end.Sub(start) / 2)
+	system := coordinate.TrueOfDate(mid.JD(astrotime.TT), coordinate.PRECESSION_NUTATION_FAST)
+	steps, err := p.steps(start, end, system)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := make(Summary, 0, len(targets))
+	for i := range targets {
+		v, err := p.visibility(&targets[i], steps, system)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", targets[i].Name, err)
+		}
+		summary = append(summary, v)
+	}
+	return summary, nil
+}
+
+func (p *Planner) steps(start, end astrotime.Instant, system string) ([]*step, error) {
+	steps := make([]*step, 0, int(end.Sub(start)/p.Step)+1)
+	for t := start; !t.After(end); t = t.Add(p.Step) {
+		st, err := p.stepAt(t, system)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, st)
+	}
+	return steps, nil
+}
+
+func (p *Planner) stepAt(t astrotime.Instant, system string) (*step, error) {
+	o := p.Observatory
+	sun, err := coordinate.Sun(o, t, coordinate.PRECISION_LOW)
+	if err != nil {
+		return nil, err
+	}
+	if sun, err = sun.Convert(system); err != nil {
+		return nil, err
+	}
+	moon, err := coordinate.Moon(o, t, coordinate.PRECISION_MEDIUM)
+	if err != nil {
+		return nil, err
+	}
+	if moon, err = moon.Convert(system); err != nil {
+		return nil, err
+	}
+	lst := coordinate.LST(o, t)
+	return &step{time: t, lst: lst, sun: sun, sunElevation: coordinate.Elevation(lst, sun, o), moon: moon}, nil
+}
+
+func (p *Planner) visibility(target *Target, steps []*step, system string) (*Visibility, error) {
+	c, err := target.Coord.Convert(system)
+	if err != nil {
+		return nil, err
+	}
+	v := &Visibility{Target: *target}
+	var window *coordinate.Interval
+	for _, st := range steps {
+		ok, el := p.check(target, c, st)
+		if !ok {
+			window = nil
+			continue
+		}
+		if window == nil {
+			window = &coordinate.Interval{Start: st.time, End: st.time}
+			v.Windows = append(v.Windows, window)
+		}
+		window.End = st.time
+		if v.MaxElevationTime == (astrotime.Instant{}) || el > v.MaxElevation {
+			v.MaxElevation = el
+			v.MaxElevationTime = st.time
+		}
+	}
+	return v, nil
+}
+
+// check returns whether the target at c (of date) satisfies the constraints at st,
+// and its observed elevation.
+func (p *Planner) check(target *Target, c coordinate.Coordinate, st *step) (bool, float64) {
+	el := coordinate.ObservedElevation(st.lst, c, p.Observatory, p.Refraction)
+	az := coordinate.Azimuth(st.lst, c, p.Observatory)
+	s := &Sample{
+		Time:         st.time,
+		LST:          st.lst,
+		Target:       target,
+		Coord:        c,
+		Elevation:    el,
+		Azimuth:      az,
+		Horizon:      p.Observatory.Horizon(coordinate.NewAngle(az)).Degree(),
+		Sun:          st.sun,
+		SunElevation: st.sunElevation,
+		Moon:         st.moon,
+	}
+	return p.satisfied(s), el
+}
+
+func (p *Planner) satisfied(s *Sample) bool {
+	for _, c := range p.Constraints {
+		if !c.Satisfied(s) {
+			return false
+		}
+	}
+	return true
+}
+
+/* Summary */
+// Summary is the visibility of the targets, which can be sorted in place.
+type Summary []*Visibility
+
+func (s Summary) SortByName() {
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Target.Name < s[j].Target.Name })
+}
+
+// SortByTotal sorts the targets by the total length of the windows, the longest first.
+func (s Summary) SortByTotal() {
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Total() > s[j].Total() })
+}
+
+// SortByMaxElevation sorts the targets by the highest elevation, the highest first.
+func (s Summary) SortByMaxElevation() {
+	sort.SliceStable(s, func(i, j int) bool { return s[i].MaxElevation > s[j].MaxElevation })
+}
+
+// SortByStart sorts the targets by the beginning of the first window.
+// Targets that are not observable come last.
+func (s Summary) SortByStart() {
+	sort.SliceStable(s, func(i, j int) bool {
+		if !s[i].Observable() || !s[j].Observable() {
+			return s[i].Observable()
+		}
+		return s[i].Windows[0].Start.Before(s[j].Windows[0].Start)
+	})
+}
+
+// Observable returns the targets that have at least one window.
+func (s Summary) Observable() Summary {
+	observable := make(Summary, 0, len(s))
+	for _, v := range s {
+		if v.Observable() {
+			observable = append(observable, v)
+		}
+	}
+	return observable
+}
+
+func (s Summary) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%-24s %10s %8s %-23s %s\n", `# name`, `total[h]`, `maxEl`, `maxEl time`, `windows`)
+	for _, v := range s {
+		fmt.Fprintf(&buf, "%-24s %10.2f %8.2f", v.Target.Name, v.Total().Hours(), v.MaxElevation)
+		if !v.Observable() {
+			fmt.Fprintf(&buf, " %-23s -\n", `-`)
+			continue
+		}
+		fmt.Fprintf(&buf, " %-23s", v.MaxElevationTime.Time().Format(`2006-01-02T15:04:05`))
+		for i, w := range v.Windows {
+			if i > 0 {
+				buf.WriteString(`,`)
+			}
+			fmt.Fprintf(&buf, " %s/%s", w.Start.Time().Format(`2006-01-02T15:04`), w.End.Time().Format(`2006-01-02T15:04`))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
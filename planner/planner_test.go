@@ -0,0 +1,69 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yurutaso/astro/astrotime"
+	"github.com/yurutaso/astro/coordinate"
+)
+
+var (
+	testStart = astrotime.FromTime(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	testEnd   = testStart.Add(24 * time.Hour)
+)
+
+func TestPlan(t *testing.T) {
+	// At the latitude of 35.9 deg, a source at Dec +80 deg never sets below 25.9 deg,
+	// and one at Dec -60 deg never rises.
+	p := NewPlanner(coordinate.NRO(), &ElevationConstraint{Min: 20., Max: 90.})
+	targets := []Target{
+		{Name: `circumpolar`, Coord: coordinate.NewCoordinate(coordinate.SYSTEM_J2000, 30., 80.)},
+		{Name: `south`, Coord: coordinate.NewCoordinate(coordinate.SYSTEM_J2000, 30., -60.)},
+		{Name: `equator`, Coord: coordinate.NewCoordinate(coordinate.SYSTEM_J2000, 30., 0.)},
+	}
+	summary, err := p.Plan(targets, testStart, testEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := summary[0]; len(v.Windows) != 1 || v.Total() != 24*time.Hour {
+		t.Errorf("circumpolar: %d windows, %s", len(v.Windows), v.Total())
+	}
+	if v := summary[1]; v.Observable() {
+		t.Errorf("south: %d windows", len(v.Windows))
+	}
+	// The equator is above 20 deg for about 8.7 h a day, culminating at 54.1 deg.
+	if v := summary[2]; v.Total() < 8*time.Hour || v.Total() > 10*time.Hour {
+		t.Errorf("equator: %s", v.Total())
+	}
+	if v := summary[2]; v.MaxElevation < 53. || v.MaxElevation > 54.5 {
+		t.Errorf("equator: max elevation %g deg", v.MaxElevation)
+	}
+}
+
+func TestHorizonConstraint(t *testing.T) {
+	// A mask at 30 deg over the southern sky hides the culmination of a source at Dec -20 deg (34.1 deg),
+	// except for the 4 deg above the mask.
+	o := coordinate.NewObservatoryWithHorizon(coordinate.NRO_LATITUDE, coordinate.NRO_LONGITUDE, coordinate.NRO_HEIGHT,
+		`Asia/Tokyo`, `masked`, []coordinate.HorizonPoint{{Azimuth: 90., Elevation: 0.}, {Azimuth: 100., Elevation: 30.}, {Azimuth: 260., Elevation: 30.}, {Azimuth: 270., Elevation: 0.}})
+	targets := []Target{{Name: `south`, Coord: coordinate.NewCoordinate(coordinate.SYSTEM_J2000, 30., -20.)}}
+	open, err := NewPlanner(coordinate.NRO(), &ElevationConstraint{Min: 0., Max: 90.}).Plan(targets, testStart, testEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked, err := NewPlanner(o, &HorizonConstraint{}).Plan(targets, testStart, testEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !masked[0].Observable() || masked[0].Total() >= open[0].Total()/2 {
+		t.Errorf("%s above the mask, %s above the horizon", masked[0].Total(), open[0].Total())
+	}
+	if masked[0].MaxElevation < 30. {
+		t.Errorf("max elevation %g deg below the mask", masked[0].MaxElevation)
+	}
+	if hidden, err := NewPlanner(o, &HorizonConstraint{Margin: 5.}).Plan(targets, testStart, testEnd); err != nil {
+		t.Fatal(err)
+	} else if hidden[0].Observable() {
+		t.Errorf("%s less than 5 deg above the mask", hidden[0].Total())
+	}
+}
@@ -0,0 +1,391 @@
+package planner
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yurutaso/astro/astrotime"
+	"github.com/yurutaso/astro/coordinate"
+)
+
+const (
+	STRATEGY_GREEDY    string = `greedy`    // the feasible request of the highest priority next
+	STRATEGY_LOOKAHEAD string = `lookahead` // the first of the best sequence of Depth requests
+
+	DEFAULT_LOOKAHEAD_DEPTH int = 3
+	// Requests of the highest priorities considered at each level of the look-ahead
+	LOOKAHEAD_CANDIDATES int = 6
+)
+
+/* Scheduler */
+// Request is a target to be observed for Duration. Higher Priority is scheduled first.
+type Request struct {
+	Target   Target
+	Duration time.Duration
+	Priority float64
+}
+
+// SlewModel gives the time to move the antenna, with the axes driven simultaneously
+// at AzRate and ElRate [deg/s], followed by Settle.
+type SlewModel struct {
+	AzRate float64
+	ElRate float64
+	Settle time.Duration
+}
+
+// SlewTime returns the time to move from one horizontal position to another.
+// The azimuth takes the shorter way, ignoring the cable wrap.
+func (m *SlewModel) SlewTime(from, to *coordinate.AltAz) (time.Duration, error) {
+	if err := m.validate(); err != nil {
+		return 0, err
+	}
+	daz := math.Abs(from.Az.Degree() - to.Az.Degree())
+	if daz > 180. {
+		daz = 360. - daz
+	}
+	del := math.Abs(from.El.Degree() - to.El.Degree())
+	sec := math.Max(daz/m.AzRate, del/m.ElRate)
+	return time.Duration(sec*float64(time.Second)) + m.Settle, nil
+}
+
+func (m *SlewModel) validate() error {
+	if m.AzRate <= 0. || m.ElRate <= 0. {
+		return fmt.Errorf("invalid slew rates %g, %g deg/s", m.AzRate, m.ElRate)
+	}
+	if m.Settle < 0 {
+		return fmt.Errorf("invalid settle time %s", m.Settle)
+	}
+	return nil
+}
+
+// Calibration inserts a scan of Duration on one of the Calibrators, the closest one
+// that is observable, whenever the science would run longer than Cadence since the last one.
+type Calibration struct {
+	Calibrators []Target
+	Cadence     time.Duration
+	Duration    time.Duration
+}
+
+// Block is a scan in the timeline. The antenna slews for Slew before Start.
+type Block struct {
+	Target     Target
+	Start      astrotime.Instant
+	End        astrotime.Instant
+	Slew       time.Duration
+	Calibrator bool
+	Elevation  float64 // observed elevation at Start
+}
+
+type Timeline []*Block
+
+func (tl Timeline) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%-19s %-19s %8s %-24s %6s %s\n", `# start`, `end`, `slew[s]`, `target`, `el`, `type`)
+	for _, b := range tl {
+		kind := `science`
+		if b.Calibrator {
+			kind = `calibrator`
+		}
+		fmt.Fprintf(&buf, "%-19s %-19s %8.0f %-24s %6.2f %s\n",
+			b.Start.Time().Format(`2006-01-02T15:04:05`), b.End.Time().Format(`2006-01-02T15:04:05`),
+			b.Slew.Seconds(), b.Target.Name, b.Elevation, kind)
+	}
+	return buf.String()
+}
+
+// Scheduler orders requests in a session, keeping every scan within the constraints
+// of the Planner during the whole scan. Without a SlewModel, slewing takes no time.
+type Scheduler struct {
+	*Planner
+	Slew        *SlewModel
+	Calibration *Calibration
+	Strategy    string
+	Depth       int
+}
+
+func NewScheduler(o coordinate.Observatory, constraints ...Constraint) *Scheduler {
+	return &Scheduler{
+		Planner:  NewPlanner(o, constraints...),
+		Strategy: STRATEGY_GREEDY,
+		Depth:    DEFAULT_LOOKAHEAD_DEPTH,
+	}
+}
+
+// session is the state of a scheduling run.
+type session struct {
+	*Scheduler
+	system string
+	start  astrotime.Instant
+	end    astrotime.Instant
+	coords map[*Target]coordinate.Coordinate
+	steps  map[astrotime.Instant]*step
+}
+
+// Schedule returns the timeline of the requests between start and end.
+// Each request is observed at most once. When nothing is observable,
+// the session waits for Step. Requests that never fit are left out.
+func (s *Scheduler) Schedule(requests []Request, start, end astrotime.Instant) (Timeline, error) {
+	if s.Step <= 0 {
+		return nil, fmt.Errorf("invalid step %s", s.Step)
+	}
+	if s.Strategy != STRATEGY_GREEDY && s.Strategy != STRATEGY_LOOKAHEAD {
+		return nil, fmt.Errorf("unknown strategy %q", s.Strategy)
+	}
+	if s.Slew != nil {
+		if err := s.Slew.validate(); err != nil {
+			return nil, err
+		}
+	}
+	mid := start.Add(end.Sub(start) / 2)
+	ss := &session{
+		Scheduler: s,
+		system:    coordinate.TrueOfDate(mid.JD(astrotime.TT), coordinate.PRECESSION_NUTATION_FAST),
+		start:     start,
+		end:       end,
+		coords:    map[*Target]coordinate.Coordinate{},
+		steps:     map[astrotime.Instant]*step{},
+	}
+	remaining := make([]*Request, len(requests))
+	for i := range requests {
+		remaining[i] = &requests[i]
+	}
+
+	timeline := make(Timeline, 0)
+	var pos *coordinate.AltAz
+	var lastCal *astrotime.Instant
+	for t := start; t.Before(end) && len(remaining) > 0; {
+		i, b, err := ss.next(remaining, t, pos)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			t = t.Add(s.Step)
+			continue
+		}
+		if ss.needsCalibration(timeline, lastCal, b) {
+			cal, err := ss.calibrator(t, pos)
+			if err != nil {
+				return nil, err
+			}
+			if cal != nil {
+				b = cal
+			}
+		}
+		if !b.Calibrator {
+			remaining = append(remaining[:i], remaining[i+1:]...)
+		} else {
+			lastCal = &b.End
+		}
+		timeline = append(timeline, b)
+		if pos, err = coordinate.ToAltAz(b.Target.Coord, s.Observatory, b.End); err != nil {
+			return nil, err
+		}
+		t = b.End
+	}
+	return timeline, nil
+}
+
+func (ss *session) needsCalibration(timeline Timeline, lastCal *astrotime.Instant, b *Block) bool {
+	if ss.Calibration == nil || len(ss.Calibration.Calibrators) == 0 {
+		return false
+	}
+	if len(timeline) > 0 && timeline[len(timeline)-1].Calibrator {
+		return false
+	}
+	return lastCal == nil || b.End.Sub(*lastCal) > ss.Calibration.Cadence
+}
+
+// calibrator returns the calibrator scan of the shortest slew at t, or nil if none is observable.
+func (ss *session) calibrator(t astrotime.Instant, pos *coordinate.AltAz) (*Block, error) {
+	var best *Block
+	for i := range ss.Calibration.Calibrators {
+		b, err := ss.place(&ss.Calibration.Calibrators[i], ss.Calibration.Duration, t, pos)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil && (best == nil || b.Slew < best.Slew) {
+			best = b
+		}
+	}
+	if best != nil {
+		best.Calibrator = true
+	}
+	return best, nil
+}
+
+// next returns the index in remaining and the block of the request to observe at t.
+func (ss *session) next(remaining []*Request, t astrotime.Instant, pos *coordinate.AltAz) (int, *Block, error) {
+	greedy := ss.Strategy == STRATEGY_GREEDY
+	cands, blocks, err := ss.candidates(remaining, t, pos, len(remaining), greedy)
+	if err != nil || len(cands) == 0 {
+		return -1, nil, err
+	}
+	if greedy {
+		return cands[0], blocks[0], nil
+	}
+
+	best, bestScore := 0, math.Inf(-1)
+	var bestFinish astrotime.Instant
+	for k := 0; k < len(cands) && k < LOOKAHEAD_CANDIDATES; k++ {
+		rest := append(append([]*Request{}, remaining[:cands[k]]...), remaining[cands[k]+1:]...)
+		score, finish, err := ss.lookahead(rest, blocks[k], ss.Depth-1)
+		if err != nil {
+			return -1, nil, err
+		}
+		score += remaining[cands[k]].Priority
+		if score > bestScore || score == bestScore && finish.Before(bestFinish) {
+			best, bestScore, bestFinish = k, score, finish
+		}
+	}
+	return cands[best], blocks[best], nil
+}
+
+// lookahead returns the best total priority of depth more requests after the block b
+// and the time the sequence finishes.
+func (ss *session) lookahead(remaining []*Request, b *Block, depth int) (float64, astrotime.Instant, error) {
+	if depth <= 0 || len(remaining) == 0 {
+		return 0., b.End, nil
+	}
+	pos, err := coordinate.ToAltAz(b.Target.Coord, ss.Observatory, b.End)
+	if err != nil {
+		return 0., b.End, err
+	}
+	cands, blocks, err := ss.candidates(remaining, b.End, pos, LOOKAHEAD_CANDIDATES, false)
+	if err != nil || len(cands) == 0 {
+		return 0., b.End, err
+	}
+	bestScore, bestFinish := math.Inf(-1), b.End
+	for k, i := range cands {
+		rest := append(append([]*Request{}, remaining[:i]...), remaining[i+1:]...)
+		score, finish, err := ss.lookahead(rest, blocks[k], depth-1)
+		if err != nil {
+			return 0., b.End, err
+		}
+		score += remaining[i].Priority
+		if score > bestScore || score == bestScore && finish.Before(bestFinish) {
+			bestScore, bestFinish = score, finish
+		}
+	}
+	return bestScore, bestFinish, nil
+}
+
+// candidates returns up to n requests observable from t, ordered by priority
+// and then by the slew time, with their blocks. If firstTier, only the requests
+// of the highest priority that has an observable one are returned.
+func (ss *session) candidates(remaining []*Request, t astrotime.Instant, pos *coordinate.AltAz, n int, firstTier bool) ([]int, []*Block, error) {
+	order := make([]int, len(remaining))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return remaining[order[i]].Priority > remaining[order[j]].Priority })
+
+	type candidate struct {
+		index int
+		block *Block
+	}
+	found := make([]candidate, 0, n)
+	for _, i := range order {
+		if len(found) >= n {
+			break
+		}
+		if firstTier && len(found) > 0 && remaining[i].Priority < remaining[found[0].index].Priority {
+			break
+		}
+		b, err := ss.place(&remaining[i].Target, remaining[i].Duration, t, pos)
+		if err != nil {
+			return nil, nil, err
+		}
+		if b != nil {
+			found = append(found, candidate{i, b})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		pi, pj := remaining[found[i].index].Priority, remaining[found[j].index].Priority
+		if pi != pj {
+			return pi > pj
+		}
+		return found[i].block.Slew < found[j].block.Slew
+	})
+	cands := make([]int, len(found))
+	blocks := make([]*Block, len(found))
+	for k, c := range found {
+		cands[k], blocks[k] = c.index, c.block
+	}
+	return cands, blocks, nil
+}
+
+// place returns the block of target for dur after slewing from pos at t,
+// or nil if the target violates the constraints during the scan or the session ends.
+// The constraints are checked at the start, the end and the steps of the session between them.
+func (ss *session) place(target *Target, dur time.Duration, t astrotime.Instant, pos *coordinate.AltAz) (*Block, error) {
+	var slew time.Duration
+	if pos != nil && ss.Slew != nil {
+		to, err := coordinate.ToAltAz(target.Coord, ss.Observatory, t)
+		if err != nil {
+			return nil, err
+		}
+		if slew, err = ss.Slew.SlewTime(pos, to); err != nil {
+			return nil, err
+		}
+	}
+	start := t.Add(slew)
+	end := start.Add(dur)
+	if end.After(ss.end) {
+		return nil, nil
+	}
+
+	c, ok := ss.coords[target]
+	if !ok {
+		var err error
+		if c, err = target.Coord.Convert(ss.system); err != nil {
+			return nil, err
+		}
+		ss.coords[target] = c
+	}
+	b := &Block{Target: *target, Start: start, End: end, Slew: slew}
+	for s := start; ; s = ss.nextStep(s) {
+		if s.After(end) {
+			s = end
+		}
+		st, err := ss.step(s)
+		if err != nil {
+			return nil, err
+		}
+		ok, el := ss.check(target, c, st)
+		if !ok {
+			return nil, nil
+		}
+		if s == start {
+			b.Elevation = el
+		}
+		if s == end {
+			return b, nil
+		}
+	}
+}
+
+// nextStep returns the first step of the session after t.
+func (ss *session) nextStep(t astrotime.Instant) astrotime.Instant {
+	n := t.Sub(ss.start)/ss.Step + 1
+	next := ss.start.Add(n * ss.Step)
+	if !next.After(t) {
+		next = next.Add(ss.Step)
+	}
+	return next
+}
+
+// step returns the state at t, computed once per session.
+func (ss *session) step(t astrotime.Instant) (*step, error) {
+	if st, ok := ss.steps[t]; ok {
+		return st, nil
+	}
+	st, err := ss.stepAt(t, ss.system)
+	if err != nil {
+		return nil, err
+	}
+	ss.steps[t] = st
+	return st, nil
+}
@@ -0,0 +1,72 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yurutaso/astro/coordinate"
+)
+
+// circumpolarRequests returns requests that are observable all the time at NRO.
+func circumpolarRequests(priorities ...float64) []Request {
+	requests := make([]Request, len(priorities))
+	for i, priority := range priorities {
+		requests[i] = Request{
+			Target:   Target{Name: string(rune('A' + i)), Coord: coordinate.NewCoordinate(coordinate.SYSTEM_J2000, float64(i)*60., 80.)},
+			Duration: time.Hour,
+			Priority: priority,
+		}
+	}
+	return requests
+}
+
+func TestScheduleGreedy(t *testing.T) {
+	s := NewScheduler(coordinate.NRO(), &ElevationConstraint{Min: 20., Max: 90.})
+	s.Slew = &SlewModel{AzRate: 1., ElRate: 0.5, Settle: 10 * time.Second}
+	tl, err := s.Schedule(circumpolarRequests(1., 3., 2., 3.), testStart, testStart.Add(6*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tl) != 4 {
+		t.Fatalf("%d blocks\n%s", len(tl), tl)
+	}
+	for i, b := range tl {
+		if b.End.Sub(b.Start) != time.Hour || b.Elevation < 20. {
+			t.Errorf("block %d: %s", i, tl)
+		}
+		if i > 0 && b.Start.Before(tl[i-1].End.Add(b.Slew)) {
+			t.Errorf("block %d overlaps: %s", i, tl)
+		}
+	}
+	// Higher priorities first
+	if tl[0].Target.Name == `A` || tl[1].Target.Name == `A` || tl[2].Target.Name != `C` || tl[3].Target.Name != `A` {
+		t.Errorf("order: %s", tl)
+	}
+}
+
+func TestScheduleLookahead(t *testing.T) {
+	s := NewScheduler(coordinate.NRO(), &ElevationConstraint{Min: 20., Max: 90.})
+	s.Strategy = STRATEGY_LOOKAHEAD
+	tl, err := s.Schedule(circumpolarRequests(1., 2., 3.), testStart, testStart.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tl) != 2 {
+		t.Fatalf("%d blocks\n%s", len(tl), tl)
+	}
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	s := NewScheduler(coordinate.NRO(), &ElevationConstraint{Min: 20., Max: 90.})
+	for _, m := range []*SlewModel{{AzRate: 0., ElRate: 1.}, {AzRate: 1., ElRate: -1.}, {AzRate: 1., ElRate: 1., Settle: -time.Second}} {
+		s.Slew = m
+		if _, err := s.Schedule(circumpolarRequests(1.), testStart, testEnd); err == nil {
+			t.Errorf("no error for %+v", m)
+		}
+	}
+	s.Slew = nil
+	s.Strategy = `unknown`
+	if _, err := s.Schedule(circumpolarRequests(1.), testStart, testEnd); err == nil {
+		t.Errorf("no error for an unknown strategy")
+	}
+}
@@ -0,0 +1,41 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/yurutaso/astro/NVSS"
+	"github.com/yurutaso/astro/coordinate"
+)
+
+/* Target */
+type Target struct {
+	Name  string
+	Coord coordinate.Coordinate
+}
+
+func (t *Target) String() string {
+	return fmt.Sprintf(`%s (%s)`, t.Name, t.Coord)
+}
+
+// FromNVSS returns the sources of cat as targets named by the NVSS convention, e.g. "NVSS J053432+220158".
+func FromNVSS(cat *NVSS.Catalog) []Target {
+	targets := make([]Target, 0, len(cat.Sources))
+	for _, source := range cat.Sources {
+		targets = append(targets, Target{Name: nvssName(source.Coord), Coord: source.Coord})
+	}
+	return targets
+}
+
+func nvssName(c coordinate.Coordinate) string {
+	c = c.ConvertTo(coordinate.SYSTEM_J2000)
+	ra := math.Floor(c.GetX().Seconds())
+	dec := math.Floor(math.Abs(c.GetY().ArcSeconds()))
+	sign := `+`
+	if c.GetY().Degree() < 0 {
+		sign = `-`
+	}
+	return fmt.Sprintf(`NVSS J%02.0f%02.0f%02.0f%s%02.0f%02.0f%02.0f`,
+		math.Floor(ra/3600.), math.Floor(math.Mod(ra, 3600.)/60.), math.Mod(ra, 60.),
+		sign, math.Floor(dec/3600.), math.Floor(math.Mod(dec, 3600.)/60.), math.Mod(dec, 60.))
+}
@@ -0,0 +1,198 @@
+package spectral
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/yurutaso/astro/unit"
+	"github.com/yurutaso/astro/unit/consts"
+)
+
+const (
+	/* Velocity conventions */
+	CONVENTION_RADIO        string = `radio`        // v = c (f0 - f) / f0
+	CONVENTION_OPTICAL      string = `optical`      // v = c (f0 - f) / f
+	CONVENTION_RELATIVISTIC string = `relativistic` // v = c (f0^2 - f^2) / (f0^2 + f^2)
+
+	/* Kinds of spectral quantities */
+	AXIS_FREQUENCY  string = `frequency`
+	AXIS_WAVELENGTH string = `wavelength`
+	AXIS_ENERGY     string = `energy`
+	AXIS_VELOCITY   string = `velocity`
+)
+
+var (
+	ErrUnknownConvention = errors.New(`unknown velocity convention`)
+	ErrUnknownAxis       = errors.New(`not a spectral quantity`)
+	ErrOutOfRange        = errors.New(`spectral value out of range`)
+)
+
+var (
+	hz    = unit.Second(-1.)
+	meter = unit.Meter(1.)
+	joule = unit.NewUnitValue(1., unit.Kg(1.), unit.Meter(2.), unit.Second(-2.)).Units()
+	ms    = unit.NewUnitValue(1., unit.Meter(1.), unit.Second(-1.)).Units()
+)
+
+// AxisOf returns the kind of spectral quantity (one of AXIS_*) measured in units.
+func AxisOf(units unit.Units) (string, error) {
+	switch {
+	case units.Equal(hz):
+		return AXIS_FREQUENCY, nil
+	case units.Equal(meter):
+		return AXIS_WAVELENGTH, nil
+	case units.Equal(joule):
+		return AXIS_ENERGY, nil
+	case units.Equal(ms):
+		return AXIS_VELOCITY, nil
+	}
+	return ``, ErrUnknownAxis
+}
+
+/* Spectral axis */
+// Axis converts between frequency, wavelength, energy and velocity of a line
+// with the rest frequency RestFrequency, using Convention for the velocity.
+type Axis struct {
+	RestFrequency unit.UnitValue
+	Convention    string
+}
+
+// NewAxis returns the axis of the line at rest, which is any spectral quantity
+// except velocity, e.g. the rest wavelength.
+func NewAxis(rest unit.UnitValue, convention string) (*Axis, error) {
+	switch convention {
+	case CONVENTION_RADIO, CONVENTION_OPTICAL, CONVENTION_RELATIVISTIC:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownConvention, convention)
+	}
+	if axis, err := AxisOf(rest.Units()); err != nil || axis == AXIS_VELOCITY {
+		return nil, fmt.Errorf("rest frequency %s: %w", rest, ErrUnknownAxis)
+	}
+	f, err := frequency(rest)
+	if err != nil {
+		return nil, fmt.Errorf("rest frequency: %w", err)
+	}
+	return &Axis{RestFrequency: unit.NewUnitValue(f, hz), Convention: convention}, nil
+}
+
+// Convert returns v (frequency, wavelength, energy or velocity) expressed in units,
+// which may be of any of these kinds.
+func (a *Axis) Convert(v unit.UnitValue, units unit.Units) (unit.UnitValue, error) {
+	axis, err := AxisOf(units)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, units.SetValue(1.))
+	}
+	f, err := a.frequency(v)
+	if err != nil {
+		return nil, err
+	}
+	var converted unit.UnitValue
+	switch axis {
+	case AXIS_FREQUENCY:
+		converted = unit.NewUnitValue(f, hz)
+	case AXIS_WAVELENGTH:
+		converted = unit.NewUnitValue(consts.C().Value()/f, meter)
+	case AXIS_ENERGY:
+		converted = unit.NewUnitValue(consts.H().Value()*f, joule)
+	case AXIS_VELOCITY:
+		vel, err := a.velocity(f)
+		if err != nil {
+			return nil, err
+		}
+		converted = unit.NewUnitValue(vel, ms)
+	}
+	return converted.As(units)
+}
+
+func (a *Axis) Frequency(v unit.UnitValue) (unit.UnitValue, error) {
+	return a.Convert(v, hz)
+}
+
+func (a *Axis) Wavelength(v unit.UnitValue) (unit.UnitValue, error) {
+	return a.Convert(v, meter)
+}
+
+func (a *Axis) Energy(v unit.UnitValue) (unit.UnitValue, error) {
+	return a.Convert(v, joule)
+}
+
+func (a *Axis) Velocity(v unit.UnitValue) (unit.UnitValue, error) {
+	return a.Convert(v, ms)
+}
+
+// frequency returns v converted into frequency in Hz.
+func (a *Axis) frequency(v unit.UnitValue) (float64, error) {
+	if axis, _ := AxisOf(v.Units()); axis != AXIS_VELOCITY {
+		return frequency(v)
+	}
+	si, _ := v.As(ms)
+	beta := si.Value() / consts.C().Value()
+	f0 := a.RestFrequency.Value()
+	switch a.Convention {
+	case CONVENTION_RADIO:
+		if 1.-beta <= 0. {
+			return 0., fmt.Errorf("%w: %s", ErrOutOfRange, v)
+		}
+		return f0 * (1. - beta), nil
+	case CONVENTION_OPTICAL:
+		if beta <= -1. {
+			return 0., fmt.Errorf("%w: %s", ErrOutOfRange, v)
+		}
+		return f0 / (1. + beta), nil
+	case CONVENTION_RELATIVISTIC:
+		if math.Abs(beta) >= 1. {
+			return 0., fmt.Errorf("%w: %s", ErrOutOfRange, v)
+		}
+		return f0 * math.Sqrt((1.-beta)/(1.+beta)), nil
+	}
+	return 0., fmt.Errorf("%w %q", ErrUnknownConvention, a.Convention)
+}
+
+// velocity returns the velocity in m/s of the frequency f in Hz.
+func (a *Axis) velocity(f float64) (float64, error) {
+	c := consts.C().Value()
+	f0 := a.RestFrequency.Value()
+	switch a.Convention {
+	case CONVENTION_RADIO:
+		return c * (f0 - f) / f0, nil
+	case CONVENTION_OPTICAL:
+		if f <= 0. {
+			return 0., ErrOutOfRange
+		}
+		return c * (f0 - f) / f, nil
+	case CONVENTION_RELATIVISTIC:
+		return c * (f0*f0 - f*f) / (f0*f0 + f*f), nil
+	}
+	return 0., fmt.Errorf("%w %q", ErrUnknownConvention, a.Convention)
+}
+
+// frequency returns the frequency in Hz of the frequency, wavelength or energy v,
+// which must be positive.
+func frequency(v unit.UnitValue) (float64, error) {
+	axis, err := AxisOf(v.Units())
+	if err != nil {
+		return 0., fmt.Errorf("%w: %s", err, v)
+	}
+	var si unit.UnitValue
+	switch axis {
+	case AXIS_FREQUENCY:
+		si, _ = v.As(hz)
+	case AXIS_WAVELENGTH:
+		si, _ = v.As(meter)
+	case AXIS_ENERGY:
+		si, _ = v.As(joule)
+	default:
+		return 0., fmt.Errorf("%w: %s", ErrUnknownAxis, v)
+	}
+	if si.Value() <= 0. {
+		return 0., fmt.Errorf("%w: %s", ErrOutOfRange, v)
+	}
+	switch axis {
+	case AXIS_WAVELENGTH:
+		return consts.C().Value() / si.Value(), nil
+	case AXIS_ENERGY:
+		return si.Value() / consts.H().Value(), nil
+	}
+	return si.Value(), nil
+}
@@ -0,0 +1,88 @@
+package spectral
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/yurutaso/astro/unit"
+)
+
+func TestVelocity(t *testing.T) {
+	// CO J=1-0 observed at 115.2 GHz
+	rest := unit.NewUnitValue(115.2712018e9, hz)
+	observed := unit.NewUnitValue(115.2e9, hz)
+	kms := unit.NewUnitValue(1., unit.KiloMeter(1.), unit.Second(-1.)).Units()
+	cases := []struct {
+		convention string
+		v          float64
+	}{
+		{CONVENTION_RADIO, 185.1786},
+		{CONVENTION_OPTICAL, 185.2931},
+		{CONVENTION_RELATIVISTIC, 185.2358},
+	}
+	for _, c := range cases {
+		a, err := NewAxis(rest, c.convention)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := a.Convert(observed, kms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(v.Value()-c.v) > 1e-3 {
+			t.Errorf("%s: %g km/s", c.convention, v.Value())
+		}
+		f, err := a.Frequency(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(f.Value()-115.2e9) > 1. {
+			t.Errorf("%s: back to %g Hz", c.convention, f.Value())
+		}
+	}
+}
+
+func TestWavelength(t *testing.T) {
+	a, err := NewAxis(unit.NewUnitValue(2.6e-3, meter), CONVENTION_RADIO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := a.RestFrequency.Value(); math.Abs(f-299792458./2.6e-3) > 1e-3 {
+		t.Errorf("rest frequency %g Hz", f)
+	}
+	e, err := a.Energy(a.RestFrequency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := e.Value() - 6.62607015e-34*a.RestFrequency.Value(); math.Abs(d) > 1e-30 {
+		t.Errorf("energy off by %g J", d)
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	c := 299792458.
+	for _, rest := range []unit.UnitValue{unit.NewUnitValue(0., hz), unit.NewUnitValue(-1., meter), unit.NewUnitValue(0., joule)} {
+		if _, err := NewAxis(rest, CONVENTION_RADIO); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("rest %s: %v", rest, err)
+		}
+	}
+	cases := []struct {
+		convention string
+		v          float64
+	}{
+		{CONVENTION_RADIO, c},
+		{CONVENTION_OPTICAL, -c},
+		{CONVENTION_RELATIVISTIC, c},
+		{CONVENTION_RELATIVISTIC, -c},
+	}
+	for _, cs := range cases {
+		a, err := NewAxis(unit.NewUnitValue(1e11, hz), cs.convention)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := a.Frequency(unit.NewUnitValue(cs.v, ms)); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("%s at %g m/s: %v", cs.convention, cs.v, err)
+		}
+	}
+}
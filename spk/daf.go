@@ -0,0 +1,114 @@
+package spk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	RECORD_SIZE int = 1024 // bytes
+)
+
+var (
+	ErrInvalidFile = errors.New(`invalid DAF/SPK file`)
+)
+
+/* DAF (Double precision Array File) */
+// daf reads the summaries and the double precision words of a DAF file.
+type daf struct {
+	r     io.ReaderAt
+	order binary.ByteOrder
+	nd    int
+	ni    int
+	fward int
+}
+
+// summary is a DAF array summary: nd doubles, ni integers and the name.
+type summary struct {
+	doubles  []float64
+	integers []int
+	name     string
+}
+
+func newDAF(r io.ReaderAt) (*daf, error) {
+	rec := make([]byte, RECORD_SIZE)
+	if _, err := r.ReadAt(rec, 0); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if id := string(rec[:8]); !strings.HasPrefix(id, `DAF/`) && id != `NAIF/DAF` {
+		return nil, fmt.Errorf("%w: file ID %q", ErrInvalidFile, id)
+	}
+	d := &daf{r: r}
+	switch format := string(rec[88:96]); format {
+	case `LTL-IEEE`:
+		d.order = binary.LittleEndian
+	case `BIG-IEEE`:
+		d.order = binary.BigEndian
+	default:
+		// Files older than the format string: ND is always 2 for SPK.
+		d.order = binary.LittleEndian
+		if binary.LittleEndian.Uint32(rec[8:12]) != 2 {
+			d.order = binary.BigEndian
+		}
+	}
+	d.nd = int(int32(d.order.Uint32(rec[8:12])))
+	d.ni = int(int32(d.order.Uint32(rec[12:16])))
+	d.fward = int(int32(d.order.Uint32(rec[76:80])))
+	if d.nd <= 0 || d.ni < 2 || d.fward <= 0 {
+		return nil, fmt.Errorf("%w: ND=%d, NI=%d, FWARD=%d", ErrInvalidFile, d.nd, d.ni, d.fward)
+	}
+	return d, nil
+}
+
+// summaries returns all array summaries, following the linked summary records.
+func (d *daf) summaries() ([]summary, error) {
+	words := d.nd + (d.ni+1)/2
+	summaries := make([]summary, 0)
+	rec := make([]byte, RECORD_SIZE)
+	names := make([]byte, RECORD_SIZE)
+	for next := d.fward; next > 0; {
+		if _, err := d.r.ReadAt(rec, int64(next-1)*int64(RECORD_SIZE)); err != nil {
+			return nil, fmt.Errorf("%w: summary record %d: %v", ErrInvalidFile, next, err)
+		}
+		if _, err := d.r.ReadAt(names, int64(next)*int64(RECORD_SIZE)); err != nil {
+			return nil, fmt.Errorf("%w: name record %d: %v", ErrInvalidFile, next+1, err)
+		}
+		control := d.doubles(rec[:24])
+		n := int(control[2])
+		if 3+n*words > RECORD_SIZE/8 {
+			return nil, fmt.Errorf("%w: %d summaries in record %d", ErrInvalidFile, n, next)
+		}
+		for i := 0; i < n; i++ {
+			b := rec[24+i*words*8 : 24+(i+1)*words*8]
+			s := summary{doubles: d.doubles(b[:d.nd*8]), integers: make([]int, d.ni)}
+			for j := range s.integers {
+				s.integers[j] = int(int32(d.order.Uint32(b[d.nd*8+j*4:])))
+			}
+			s.name = strings.TrimRight(string(names[i*words*8:(i+1)*words*8]), "\x00 ")
+			summaries = append(summaries, s)
+		}
+		next = int(control[0])
+	}
+	return summaries, nil
+}
+
+// read returns the n double precision words starting at the 1-based word address addr.
+func (d *daf) read(addr, n int) ([]float64, error) {
+	b := make([]byte, 8*n)
+	if _, err := d.r.ReadAt(b, int64(addr-1)*8); err != nil {
+		return nil, fmt.Errorf("%w: words %d-%d: %v", ErrInvalidFile, addr, addr+n-1, err)
+	}
+	return d.doubles(b), nil
+}
+
+func (d *daf) doubles(b []byte) []float64 {
+	v := make([]float64, len(b)/8)
+	for i := range v {
+		v[i] = math.Float64frombits(d.order.Uint64(b[8*i:]))
+	}
+	return v
+}
@@ -0,0 +1,266 @@
+package spk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/yurutaso/astro/astrotime"
+)
+
+const (
+	/* NAIF integer codes of the bodies in the JPL planetary ephemerides */
+	SOLAR_SYSTEM_BARYCENTER int = 0
+	MERCURY_BARYCENTER      int = 1
+	VENUS_BARYCENTER        int = 2
+	EARTH_MOON_BARYCENTER   int = 3
+	MARS_BARYCENTER         int = 4
+	JUPITER_BARYCENTER      int = 5
+	SATURN_BARYCENTER       int = 6
+	URANUS_BARYCENTER       int = 7
+	NEPTUNE_BARYCENTER      int = 8
+	PLUTO_BARYCENTER        int = 9
+	SUN                     int = 10
+	MERCURY                 int = 199
+	VENUS                   int = 299
+	MOON                    int = 301
+	EARTH                   int = 399
+
+	/* Segment data types */
+	TYPE_CHEBYSHEV_POSITION          int = 2
+	TYPE_CHEBYSHEV_POSITION_VELOCITY int = 3
+
+	SEC_PER_DAY float64 = 86400.
+)
+
+var (
+	ErrNoSegment = errors.New(`no SPK segment`)
+)
+
+/* Kernel */
+// Kernel is an SPK file of Chebyshev segments (types 2 and 3), e.g. JPL DE430.
+// Positions are in km and velocities in km/s, referred to the frame of the segments
+// (ICRF for the DE ephemerides).
+type Kernel struct {
+	file     *os.File
+	daf      *daf
+	segments []*Segment
+}
+
+// Segment is an SPK array giving the state of Target relative to Center
+// between Start and End (TDB seconds from J2000).
+type Segment struct {
+	Name   string
+	Target int
+	Center int
+	Frame  int
+	Type   int
+	Start  float64
+	End    float64
+
+	begin, end int // word addresses of the data
+	directory  []float64
+}
+
+func Open(filename string) (*Kernel, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDAF(fp)
+	if err != nil {
+		fp.Close()
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	summaries, err := d.summaries()
+	if err != nil {
+		fp.Close()
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	k := &Kernel{file: fp, daf: d}
+	for _, s := range summaries {
+		if len(s.doubles) != 2 || len(s.integers) != 6 {
+			fp.Close()
+			return nil, fmt.Errorf("%s: %w: not an SPK file", filename, ErrInvalidFile)
+		}
+		seg := &Segment{
+			Name:   s.name,
+			Target: s.integers[0],
+			Center: s.integers[1],
+			Frame:  s.integers[2],
+			Type:   s.integers[3],
+			Start:  s.doubles[0],
+			End:    s.doubles[1],
+			begin:  s.integers[4],
+			end:    s.integers[5],
+		}
+		if seg.Type == TYPE_CHEBYSHEV_POSITION || seg.Type == TYPE_CHEBYSHEV_POSITION_VELOCITY {
+			// INIT, INTLEN, RSIZE and N at the end of the segment
+			if seg.directory, err = d.read(seg.end-3, 4); err != nil {
+				fp.Close()
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+			if err := seg.checkDirectory(); err != nil {
+				fp.Close()
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+		}
+		k.segments = append(k.segments, seg)
+	}
+	return k, nil
+}
+
+// checkDirectory checks that the records of a Chebyshev segment fill its data.
+func (seg *Segment) checkDirectory() error {
+	intlen, rsize, n := seg.directory[1], seg.directory[2], seg.directory[3]
+	ncoef := (rsize - 2.) / float64(seg.components())
+	if !(intlen > 0.) || ncoef < 1. || ncoef != math.Trunc(ncoef) || n < 1. || n != math.Trunc(n) ||
+		float64(seg.begin)+n*rsize+4. != float64(seg.end+1) {
+		return fmt.Errorf("%w: segment %q: INTLEN=%g, RSIZE=%g, N=%g", ErrInvalidFile, seg.Name, intlen, rsize, n)
+	}
+	return nil
+}
+
+// components returns the number of the Chebyshev series in a record.
+func (seg *Segment) components() int {
+	if seg.Type == TYPE_CHEBYSHEV_POSITION_VELOCITY {
+		return 6
+	}
+	return 3
+}
+
+func (k *Kernel) Close() error {
+	return k.file.Close()
+}
+
+func (k *Kernel) Segments() []*Segment {
+	return k.segments
+}
+
+// ET returns the TDB seconds from J2000 of t, the time argument of the segments.
+func ET(t astrotime.Instant) float64 {
+	jd1, jd2 := t.JD2(astrotime.TDB)
+	return ((jd1 - astrotime.JD_J2000) + jd2) * SEC_PER_DAY
+}
+
+// State returns the position [km] and the velocity [km/s] of target relative to center at t.
+func (k *Kernel) State(target, center int, t astrotime.Instant) ([3]float64, [3]float64, error) {
+	return k.StateAt(target, center, ET(t))
+}
+
+// PositionVelocity is the same as State at jd (TDB). It makes Kernel usable as coordinate.Ephemeris.
+func (k *Kernel) PositionVelocity(target, center int, jd float64) ([3]float64, [3]float64, error) {
+	return k.StateAt(target, center, (jd-astrotime.JD_J2000)*SEC_PER_DAY)
+}
+
+// StateAt returns the state of target relative to center at et (TDB seconds from J2000),
+// chaining the segments through their centers, e.g. the Moon through the Earth-Moon barycenter.
+func (k *Kernel) StateAt(target, center int, et float64) ([3]float64, [3]float64, error) {
+	p1, v1, err := k.barycentric(target, et)
+	if err != nil {
+		return p1, v1, err
+	}
+	p2, v2, err := k.barycentric(center, et)
+	if err != nil {
+		return p2, v2, err
+	}
+	for i := 0; i < 3; i++ {
+		p1[i] -= p2[i]
+		v1[i] -= v2[i]
+	}
+	return p1, v1, nil
+}
+
+// barycentric returns the state of body relative to the solar system barycenter.
+func (k *Kernel) barycentric(body int, et float64) ([3]float64, [3]float64, error) {
+	var p, v [3]float64
+	visited := map[int]bool{}
+	for body != SOLAR_SYSTEM_BARYCENTER {
+		if visited[body] {
+			return p, v, fmt.Errorf("%w: the centers of body %d form a cycle", ErrInvalidFile, body)
+		}
+		visited[body] = true
+		seg := k.find(body, et)
+		if seg == nil {
+			return p, v, fmt.Errorf("%w for body %d at ET %.1f", ErrNoSegment, body, et)
+		}
+		dp, dv, err := k.evaluate(seg, et)
+		if err != nil {
+			return p, v, err
+		}
+		for i := 0; i < 3; i++ {
+			p[i] += dp[i]
+			v[i] += dv[i]
+		}
+		body = seg.Center
+	}
+	return p, v, nil
+}
+
+// find returns the last segment of target covering et, as later segments take precedence.
+func (k *Kernel) find(target int, et float64) *Segment {
+	for i := len(k.segments) - 1; i >= 0; i-- {
+		seg := k.segments[i]
+		if seg.Target == target && seg.directory != nil && et >= seg.Start && et <= seg.End {
+			return seg
+		}
+	}
+	return nil
+}
+
+// evaluate returns the state in the Chebyshev record of seg covering et.
+func (k *Kernel) evaluate(seg *Segment, et float64) ([3]float64, [3]float64, error) {
+	var p, v [3]float64
+	init, intlen := seg.directory[0], seg.directory[1]
+	rsize, n := int(seg.directory[2]), int(seg.directory[3])
+	ncoef := (rsize - 2) / seg.components()
+
+	x := math.Floor((et - init) / intlen)
+	if x == float64(n) {
+		x-- // the end of the last record
+	}
+	if !(x >= 0. && x < float64(n)) {
+		return p, v, fmt.Errorf("%w: ET %.1f outside the records of segment %q", ErrInvalidFile, et, seg.Name)
+	}
+	index := int(x)
+	rec, err := k.daf.read(seg.begin+index*rsize, rsize)
+	if err != nil {
+		return p, v, err
+	}
+	mid, radius := rec[0], rec[1]
+	if !(radius > 0.) {
+		return p, v, fmt.Errorf("%w: record %d of segment %q with radius %g", ErrInvalidFile, index, seg.Name, radius)
+	}
+	s := (et - mid) / radius
+
+	// Chebyshev polynomials and their derivatives at s
+	t := make([]float64, ncoef)
+	dt := make([]float64, ncoef)
+	t[0] = 1.
+	if ncoef > 1 {
+		t[1] = s
+		dt[1] = 1.
+	}
+	for j := 2; j < ncoef; j++ {
+		t[j] = 2.*s*t[j-1] - t[j-2]
+		dt[j] = 2.*t[j-1] + 2.*s*dt[j-1] - dt[j-2]
+	}
+	for i := 0; i < 3; i++ {
+		coef := rec[2+i*ncoef : 2+(i+1)*ncoef]
+		for j, c := range coef {
+			p[i] += c * t[j]
+			v[i] += c * dt[j]
+		}
+		v[i] /= radius
+	}
+	if seg.Type == TYPE_CHEBYSHEV_POSITION_VELOCITY {
+		for i := 0; i < 3; i++ {
+			v[i] = 0.
+			for j, c := range rec[2+(3+i)*ncoef : 2+(4+i)*ncoef] {
+				v[i] += c * t[j]
+			}
+		}
+	}
+	return p, v, nil
+}
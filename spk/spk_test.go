@@ -0,0 +1,147 @@
+package spk
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSegment is a type 2 segment whose records have the same coefficients for x, y and z.
+type testSegment struct {
+	target, center int
+	init, intlen   float64
+	coefs          [][]float64 // per record
+}
+
+// writeKernel writes a little-endian SPK file of the segments with a single summary record.
+func writeKernel(t *testing.T, segments []testSegment) string {
+	words := make([]float64, 0)
+	summaries := make([]byte, 0)
+	addr := 3*RECORD_SIZE/8 + 1
+	for _, seg := range segments {
+		ncoef := len(seg.coefs[0])
+		rsize := 2 + 3*ncoef
+		begin := addr + len(words)
+		for i, coefs := range seg.coefs {
+			words = append(words, seg.init+(float64(i)+0.5)*seg.intlen, seg.intlen/2.)
+			for j := 0; j < 3; j++ {
+				words = append(words, coefs...)
+			}
+		}
+		words = append(words, seg.init, seg.intlen, float64(rsize), float64(len(seg.coefs)))
+		end := addr + len(words) - 1
+
+		b := make([]byte, 40)
+		binary.LittleEndian.PutUint64(b[0:], math.Float64bits(seg.init))
+		binary.LittleEndian.PutUint64(b[8:], math.Float64bits(seg.init+float64(len(seg.coefs))*seg.intlen))
+		for i, v := range []int{seg.target, seg.center, 1, TYPE_CHEBYSHEV_POSITION, begin, end} {
+			binary.LittleEndian.PutUint32(b[16+4*i:], uint32(int32(v)))
+		}
+		summaries = append(summaries, b...)
+	}
+
+	file := make([]byte, 3*RECORD_SIZE+8*len(words))
+	copy(file, `DAF/SPK `)
+	binary.LittleEndian.PutUint32(file[8:], 2)
+	binary.LittleEndian.PutUint32(file[12:], 6)
+	binary.LittleEndian.PutUint32(file[76:], 2)
+	copy(file[88:], `LTL-IEEE`)
+	rec := file[RECORD_SIZE:]
+	binary.LittleEndian.PutUint64(rec[16:], math.Float64bits(float64(len(segments))))
+	copy(rec[24:], summaries)
+	for i, w := range words {
+		binary.LittleEndian.PutUint64(file[3*RECORD_SIZE+8*i:], math.Float64bits(w))
+	}
+
+	filename := filepath.Join(t.TempDir(), `test.bsp`)
+	if err := os.WriteFile(filename, file, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestKernel(t *testing.T) {
+	filename := writeKernel(t, []testSegment{
+		{EARTH_MOON_BARYCENTER, SOLAR_SYSTEM_BARYCENTER, 0., 100., [][]float64{{1., 2., 3.}, {10., 20., 30.}}},
+		{EARTH, EARTH_MOON_BARYCENTER, 0., 200., [][]float64{{-5., 0., 0.}}},
+	})
+	k, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer k.Close()
+	if n := len(k.Segments()); n != 2 {
+		t.Fatalf("%d segments", n)
+	}
+
+	// In the second record, s = (125-150)/50 = -0.5, so x = 10 + 20s + 30(2s^2-1) = -15
+	// and dx/dt = (20 + 120s)/50 = -0.8.
+	p, v, err := k.StateAt(EARTH, SOLAR_SYSTEM_BARYCENTER, 125.)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if math.Abs(p[i]-(-20.)) > 1e-12 || math.Abs(v[i]-(-0.8)) > 1e-12 {
+			t.Errorf("state[%d] = %g, %g", i, p[i], v[i])
+		}
+	}
+	p, _, err = k.StateAt(SOLAR_SYSTEM_BARYCENTER, EARTH, 125.)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(p[0]-20.) > 1e-12 {
+		t.Errorf("reversed x = %g", p[0])
+	}
+	if _, _, err := k.StateAt(EARTH, SOLAR_SYSTEM_BARYCENTER, 250.); !errors.Is(err, ErrNoSegment) {
+		t.Errorf("outside the segments: %v", err)
+	}
+	if _, _, err := k.StateAt(MOON, SOLAR_SYSTEM_BARYCENTER, 50.); !errors.Is(err, ErrNoSegment) {
+		t.Errorf("without a segment: %v", err)
+	}
+}
+
+func TestKernelCycle(t *testing.T) {
+	filename := writeKernel(t, []testSegment{
+		{EARTH_MOON_BARYCENTER, EARTH, 0., 100., [][]float64{{1., 2., 3.}}},
+		{EARTH, EARTH_MOON_BARYCENTER, 0., 100., [][]float64{{-5., 0., 0.}}},
+	})
+	k, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer k.Close()
+	if _, _, err := k.StateAt(EARTH, SOLAR_SYSTEM_BARYCENTER, 50.); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("cycle of centers: %v", err)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), `invalid.bsp`)
+	if err := os.WriteFile(filename, make([]byte, RECORD_SIZE), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(filename); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("invalid file: %v", err)
+	}
+
+	// RSIZE is the third word of the directory at the end of the data.
+	for _, rsize := range []float64{-1., 0., 12., 1e300} {
+		filename := writeKernel(t, []testSegment{
+			{EARTH, SOLAR_SYSTEM_BARYCENTER, 0., 100., [][]float64{{1., 2., 3.}}},
+		})
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		binary.LittleEndian.PutUint64(b[len(b)-16:], math.Float64bits(rsize))
+		if err := os.WriteFile(filename, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(filename); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("RSIZE %g: %v", rsize, err)
+		}
+	}
+}
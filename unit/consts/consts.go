@@ -21,3 +21,8 @@ func Kb() unit.UnitValue {
 	value := 1.38064852e-23
 	return unit.NewUnitValue(value, m.Times(2), kg, s.Times(-2), k.Inverse())
 }
+
+func H() unit.UnitValue {
+	value := 6.62607015e-34
+	return unit.NewUnitValue(value, m.Times(2), kg, s.Inverse())
+}
@@ -0,0 +1,20 @@
+package consts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConstants(t *testing.T) {
+	// 1 eV corresponds to 2.417989242e14 Hz and 11604.518 K.
+	ev := 1.602176634e-19
+	if d := ev/H().Value() - 2.417989242e14; math.Abs(d) > 1e5 {
+		t.Errorf("1 eV off by %g Hz", d)
+	}
+	if d := ev/Kb().Value() - 11604.518; math.Abs(d) > 0.01 {
+		t.Errorf("1 eV off by %g K", d)
+	}
+	if c := C().Value(); c != 299792458. {
+		t.Errorf("c = %g m/s", c)
+	}
+}
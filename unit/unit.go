@@ -217,6 +217,12 @@ func Cm(dim float64) Units {
 func Meter(dim float64) Units {
 	return meter().AsUnits(dim)
 }
+func KiloMeter(dim float64) Units {
+	return BaseUnitOfLength(`km`, PREFIX_KILO).AsUnits(dim)
+}
+func Km(dim float64) Units {
+	return KiloMeter(dim)
+}
 
 // Units of time
 func Second(dim float64) Units {
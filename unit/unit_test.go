@@ -0,0 +1,20 @@
+package unit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKiloMeter(t *testing.T) {
+	v := NewUnitValue(1.5, KiloMeter(1.), Second(-1.))
+	ms, err := v.As(NewUnitValue(1., Meter(1.), Second(-1.)).Units())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(ms.Value()-1500.) > 1e-9 {
+		t.Errorf("1.5 km/s = %g m/s", ms.Value())
+	}
+	if _, err := v.As(Meter(1.)); err == nil {
+		t.Errorf("no error converting km/s into m")
+	}
+}